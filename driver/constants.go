@@ -2,5 +2,4 @@ package driver
 
 const (
 	ServiceCustomConfigName = "ServiceCustomConfig"
-	ContentPropertyName     = "Contents"
 )
@@ -13,17 +13,23 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	dsModels "github.com/edgexfoundry/device-sdk-go/v2/pkg/models"
 	"github.com/edgexfoundry/device-sdk-go/v2/pkg/service"
-	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/http"
+	bootstrapMessaging "github.com/edgexfoundry/go-mod-bootstrap/v2/bootstrap/messaging"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/interfaces"
+	contractsCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/models"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
 
 	"github.com/rddigital/device-scenario/internal/application"
+	"github.com/rddigital/device-scenario/internal/client"
+	"github.com/rddigital/device-scenario/internal/common"
 	"github.com/rddigital/device-scenario/internal/config"
 	"github.com/rddigital/device-scenario/internal/controller/rest"
+	appModels "github.com/rddigital/device-scenario/internal/models"
 )
 
 var once sync.Once
@@ -33,6 +39,8 @@ type ScenarioDriver struct {
 	lc            logger.LoggingClient
 	serviceConfig *config.ServiceConfig
 	commandClient interfaces.CommandClient
+	// executionTimeout is the deadline of the actions of a ManualScenario
+	executionTimeout time.Duration
 }
 
 type ScenarioContent struct {
@@ -70,19 +78,85 @@ func (d *ScenarioDriver) Initialize(lc logger.LoggingClient, asyncCh chan<- *dsM
 	hostService := d.serviceConfig.ServiceCustomConfig.MyserviceInfo.Host
 	portService := d.serviceConfig.ServiceCustomConfig.MyserviceInfo.Port
 	urlCoreCommand := d.serviceConfig.ServiceCustomConfig.CommandClientInfo.Url()
-	d.commandClient = http.NewCommandClient(urlCoreCommand)
+	d.commandClient = client.NewHttpCommandClient(urlCoreCommand, nil)
+	d.executionTimeout = common.DefaultRuleTimeout
+	// the timeout is already validated with the configuration
+	if ruleTimeout, err := time.ParseDuration(d.serviceConfig.ServiceCustomConfig.ExecutionInfo.RuleTimeout); err == nil {
+		d.executionTimeout = ruleTimeout
+	}
 	urlNotification := d.serviceConfig.ServiceCustomConfig.NotificationClientInfo.Url()
 	urlSchduler := d.serviceConfig.ServiceCustomConfig.SchedulerClientInfo.Url()
 	urlRuleEngine := d.serviceConfig.ServiceCustomConfig.RuleEngineClientInfo.Url()
+	metadataInfo, ok := d.serviceConfig.Clients[contractsCommon.CoreMetaDataServiceKey]
+	if !ok || metadataInfo.Host == "" || metadataInfo.Port == 0 {
+		return fmt.Errorf("'%s' client not configured in [Clients]", contractsCommon.CoreMetaDataServiceKey)
+	}
+	urlMetadata := metadataInfo.Url()
+	messageBusConfig, err := d.messageBusConfig()
+	if err != nil {
+		return fmt.Errorf("unable to load message bus configuration: %s", err.Error())
+	}
+
+	storagePath := d.serviceConfig.ServiceCustomConfig.StorageInfo.Path
+	if storagePath == "" {
+		storagePath = common.DefaultStoragePath
+	}
 
 	rest.InitRuleServer()
-	err := application.InitRuleApplication(d.lc, portService, hostService, urlCoreCommand, urlNotification, urlSchduler, urlRuleEngine)
+	err = application.InitRuleApplication(d.lc, portService, hostService, urlCoreCommand, urlNotification, urlSchduler, urlRuleEngine, urlMetadata, messageBusConfig, storagePath,
+		d.serviceConfig.ServiceCustomConfig.RemoteCommandClients, d.serviceConfig.ServiceCustomConfig.ExecutionInfo, d.serviceConfig.ServiceCustomConfig.HistoryInfo,
+		d.serviceConfig.ServiceCustomConfig.TriggerQueueInfo, d.serviceConfig.ServiceCustomConfig.StormInfo)
 	if err != nil {
 		d.lc.Errorf(err.Error())
 	}
 	return err
 }
 
+// messageBusConfig returns the configuration of the message bus used by the publish actions,
+// it returns nil if the message bus is not configured
+func (d *ScenarioDriver) messageBusConfig() (*types.MessageBusConfig, error) {
+	info := d.serviceConfig.MessageQueue
+	if info.Host == "" {
+		return nil, nil
+	}
+
+	optional := make(map[string]string, len(info.Optional)+2)
+	for key, value := range info.Optional {
+		optional[key] = value
+	}
+
+	if info.AuthMode != "" && info.AuthMode != bootstrapMessaging.AuthModeNone {
+		ds := service.RunningService()
+		secretData, err := bootstrapMessaging.GetSecretData(info.AuthMode, info.SecretName, ds.SecretProvider)
+		if err != nil {
+			return nil, err
+		}
+		if err = bootstrapMessaging.ValidateSecretData(info.AuthMode, info.SecretName, secretData); err != nil {
+			return nil, err
+		}
+		switch info.AuthMode {
+		case bootstrapMessaging.AuthModeUsernamePassword:
+			optional[bootstrapMessaging.OptionsUsernameKey] = secretData.Username
+			optional[bootstrapMessaging.OptionsPasswordKey] = secretData.Password
+		case bootstrapMessaging.AuthModeCert:
+			optional[bootstrapMessaging.OptionsCertPEMBlockKey] = string(secretData.CertPemBlock)
+			optional[bootstrapMessaging.OptionsKeyPEMBlockKey] = string(secretData.KeyPemBlock)
+		case bootstrapMessaging.AuthModeCA:
+			optional[bootstrapMessaging.OptionsCaPEMBlockKey] = string(secretData.CaPemBlock)
+		}
+	}
+
+	return &types.MessageBusConfig{
+		PublishHost: types.HostInfo{
+			Host:     info.Host,
+			Port:     info.Port,
+			Protocol: info.Protocol,
+		},
+		Type:     info.Type,
+		Optional: optional,
+	}, nil
+}
+
 func (d *ScenarioDriver) HandleReadCommands(deviceName string, protocols map[string]models.ProtocolProperties, reqs []dsModels.CommandRequest) (res []*dsModels.CommandValue, err error) {
 	return nil, fmt.Errorf("ScenarioDriver.HandleReadCommands; read commands not supported")
 }
@@ -93,7 +167,10 @@ func (d *ScenarioDriver) HandleWriteCommands(deviceName string, protocols map[st
 	for _, param := range params {
 		switch param.DeviceResourceName {
 		case "TriggerScenario":
-			content, ok := protocols[ContentPropertyName]
+			if application.IsPaused(deviceName) {
+				return fmt.Errorf("ScenarioDriver.HandleWriteCommands: automations are paused, scenario '%s' not triggered", deviceName)
+			}
+			content, ok := protocols[common.ContentsProperty]
 			if !ok {
 				d.lc.Debugf("No content in Scenario: %s", deviceName)
 				return nil
@@ -104,17 +181,25 @@ func (d *ScenarioDriver) HandleWriteCommands(deviceName string, protocols map[st
 				return nil
 			}
 
-			ctx := context.Background()
+			ctx, cancel := context.WithTimeout(context.Background(), d.executionTimeout)
+			defer cancel()
+			started := time.Now()
+			result := appModels.ExecutionResult{RuleName: deviceName}
 			arrError := make([]string, 0)
-			for _, action := range arrAction {
+			for index, action := range arrAction {
+				actionResult := appModels.ActionResult{Index: index, Type: appModels.CommandActionType, Status: appModels.ActionSucceeded}
 				_, errAction := d.commandClient.IssueSetCommandByName(ctx, action.DeviceName, action.CommandName, action.BodyMap)
 				if errAction != nil {
 					arrError = append(arrError, errAction.Message())
+					actionResult.Status = appModels.ActionFailed
+					actionResult.Error = errAction.Message()
 					d.lc.Debugf("Send command '%s' to device '%s' failed", action.CommandName, action.DeviceName)
 				} else {
 					d.lc.Debugf("Send command '%s' to device '%s' successed", action.CommandName, action.DeviceName)
 				}
+				result.AddAction(actionResult)
 			}
+			application.RecordScenarioExecution(deviceName, started, result)
 
 			if len(arrError) > 0 {
 				errStr := strings.Join(arrError, ";")
@@ -130,6 +215,7 @@ func (d *ScenarioDriver) HandleWriteCommands(deviceName string, protocols map[st
 
 func (d *ScenarioDriver) Stop(force bool) error {
 	d.lc.Info("ScenarioDriver.Stop: device-scenario driver is stopping...")
+	application.StopRuleApplication()
 	return nil
 }
 
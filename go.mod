@@ -3,8 +3,11 @@ module github.com/rddigital/device-scenario
 go 1.16
 
 require (
+	github.com/eclipse/paho.mqtt.golang v1.3.5
 	github.com/edgexfoundry/device-sdk-go/v2 v2.0.0
+	github.com/edgexfoundry/go-mod-bootstrap/v2 v2.0.0
 	github.com/edgexfoundry/go-mod-core-contracts/v2 v2.0.0
+	github.com/edgexfoundry/go-mod-messaging/v2 v2.0.1
 	github.com/go-playground/validator/v10 v10.6.1
 	github.com/google/uuid v1.2.0
 	github.com/gorilla/mux v1.8.0
@@ -0,0 +1,448 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"text/template"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/interfaces"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// triggerContext holds the values which can be used in the templates of actions
+type triggerContext struct {
+	RuleId       string
+	RuleName     string
+	TriggerIndex int
+	TriggerState bool
+	// Value, DeviceName and ResourceName are only set by threshold conditions
+	Value        interface{}
+	DeviceName   string
+	ResourceName string
+	Timestamp    int64
+	// Failures is the number of failed actions, it is only set for the notification
+	Failures int
+
+	// depth is the number of nested scenario actions
+	depth int
+	// priority is the priority of the rule, see arbitrate
+	priority int
+	// executor performs the effects of the actions
+	executor actionExecutor
+}
+
+func newTriggerContext(rule models.Rule, contentTrigger models.ContentTrigger) triggerContext {
+	tc := triggerContext{
+		RuleId:    rule.Id,
+		RuleName:  rule.Name,
+		Timestamp: time.Now().UnixNano(),
+		executor:  liveExecutor{},
+		priority:  rule.PriorityValue(),
+	}
+	if contentTrigger.TriggerIndex != nil {
+		tc.TriggerIndex = *contentTrigger.TriggerIndex
+	}
+	if contentTrigger.TriggerState != nil {
+		tc.TriggerState = *contentTrigger.TriggerState
+	}
+	tc.Value = contentTrigger.TriggerValue
+	if tc.TriggerIndex >= 0 && tc.TriggerIndex < len(rule.Conditions) {
+		if c := rule.Conditions[tc.TriggerIndex]; c.Type == cm.ThresholdRuleType {
+			tc.DeviceName = c.DeviceThreshold
+			tc.ResourceName = c.ResourceThreshold
+		}
+	}
+	return tc
+}
+
+// validateActions checks the values of the rule and its actions which can not be checked by the validator.
+// The actions equal to the oldActions at the same place were checked when they were saved and are not
+// checked again, so that a stale device never prevents a rule from being updated, locked or unlocked
+func validateActions(rule models.Rule, oldActions []models.Action) error {
+	if rule.Timeout != "" {
+		if _, err := time.ParseDuration(rule.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout '%s': %s", rule.Timeout, err.Error())
+		}
+	}
+	for i, c := range rule.Conditions {
+		if c.MaxAge == "" {
+			if c.OnExpiry != "" {
+				return fmt.Errorf("condition[%d] onExpiry without maxAge", i)
+			}
+			continue
+		}
+		if c.Type != cm.ThresholdRuleType {
+			return fmt.Errorf("condition[%d] maxAge is only supported by threshold conditions", i)
+		}
+		if _, err := time.ParseDuration(c.MaxAge); err != nil {
+			return fmt.Errorf("condition[%d] invalid maxAge '%s': %s", i, c.MaxAge, err.Error())
+		}
+		if c.OnExpiry != "" && c.OnExpiry != models.ConditionUnknown && c.OnExpiry != models.ConditionFalse {
+			return fmt.Errorf("condition[%d] onExpiry must be '%s' or '%s'", i, models.ConditionUnknown, models.ConditionFalse)
+		}
+	}
+	if reflect.DeepEqual(rule.Actions, oldActions) {
+		return nil
+	}
+	if err := validateActionList(rule.Actions, oldActions, "action", false); err != nil {
+		return err
+	}
+	return validateScenarioReferences(rule)
+}
+
+// validateActionList checks the actions and the actions of their branches, prefix is the path
+// of the list in the error messages. The actions of a branch can not be delayed. An action equal
+// to the old action at the same place is not checked again
+func validateActionList(actions []models.Action, oldActions []models.Action, prefix string, inBranch bool) error {
+	for i, action := range actions {
+		var old models.Action
+		if i < len(oldActions) {
+			old = oldActions[i]
+			if reflect.DeepEqual(action, old) {
+				continue
+			}
+		}
+		index := fmt.Sprintf("%s[%d]", prefix, i)
+		if action.Timeout != "" {
+			if _, err := time.ParseDuration(action.Timeout); err != nil {
+				return fmt.Errorf("%s invalid timeout '%s': %s", index, action.Timeout, err.Error())
+			}
+		}
+		if action.Delay != "" {
+			if inBranch {
+				return fmt.Errorf("%s delay is not supported inside a branch", index)
+			}
+			if _, err := time.ParseDuration(action.Delay); err != nil {
+				return fmt.Errorf("%s invalid delay '%s': %s", index, action.Delay, err.Error())
+			}
+		}
+		if action.Target != nil {
+			if action.ActionType() != models.CommandActionType {
+				return fmt.Errorf("%s target is only supported by command actions", index)
+			}
+			if action.Target.NamePattern != "" {
+				if _, err := path.Match(action.Target.NamePattern, ""); err != nil {
+					return fmt.Errorf("%s invalid name pattern '%s': %s", index, action.Target.NamePattern, err.Error())
+				}
+			}
+		}
+		if action.Guard != nil && action.ActionType() != models.CommandActionType {
+			return fmt.Errorf("%s guard is only supported by command actions", index)
+		}
+		if action.Verify != nil {
+			if action.ActionType() != models.CommandActionType {
+				return fmt.Errorf("%s verify is only supported by command actions", index)
+			}
+			if action.Verify.Delay != "" {
+				if _, err := time.ParseDuration(action.Verify.Delay); err != nil {
+					return fmt.Errorf("%s invalid verify delay '%s': %s", index, action.Verify.Delay, err.Error())
+				}
+			}
+		}
+		if action.Ramp != nil {
+			duration, err := time.ParseDuration(action.Ramp.Duration)
+			if err != nil {
+				return fmt.Errorf("%s invalid ramp duration '%s': %s", index, action.Ramp.Duration, err.Error())
+			}
+			if duration <= 0 {
+				return fmt.Errorf("%s ramp duration '%s' must be positive", index, action.Ramp.Duration)
+			}
+			if action.Ramp.Steps > 0 && duration/time.Duration(action.Ramp.Steps) <= 0 {
+				return fmt.Errorf("%s ramp duration '%s' is too short for %d steps", index, action.Ramp.Duration, action.Ramp.Steps)
+			}
+		}
+		if action.Webhook != nil && action.Webhook.Timeout != "" {
+			if _, err := time.ParseDuration(action.Webhook.Timeout); err != nil {
+				return fmt.Errorf("%s invalid webhook timeout '%s': %s", index, action.Webhook.Timeout, err.Error())
+			}
+		}
+		if action.Remote != "" {
+			if action.ActionType() != models.CommandActionType {
+				return fmt.Errorf("%s remote is only supported by command actions", index)
+			}
+			if action.Target != nil {
+				return fmt.Errorf("%s remote is not supported with a target", index)
+			}
+			if _, err := commandClientFor(action.Remote); err != nil {
+				return fmt.Errorf("%s %s", index, err.Error())
+			}
+			// the devices of a remote instance are not known by the local core-metadata
+			if _, err := decodeBody(action.Body); err != nil {
+				return fmt.Errorf("%s invalid body: %s", index, err.Error())
+			}
+		} else if action.ActionType() == models.CommandActionType {
+			if err := validateCommandBody(action); err != nil {
+				return fmt.Errorf("%s %s", index, err.Error())
+			}
+		}
+		for _, remote := range deviceRemotes(action) {
+			if _, err := commandClientFor(remote); err != nil {
+				return fmt.Errorf("%s %s", index, err.Error())
+			}
+		}
+		if action.If != nil && action.If.Predicate.Remote != "" && action.If.Predicate.Source != models.ResourcePredicateSource {
+			return fmt.Errorf("%s remote is only supported by %s predicates", index, models.ResourcePredicateSource)
+		}
+		if action.Wait != nil {
+			if action.Delay != "" {
+				return fmt.Errorf("%s wait can not be delayed", index)
+			}
+			if action.Wait.Predicate.Source != models.ResourcePredicateSource {
+				return fmt.Errorf("%s wait predicate source must be '%s'", index, models.ResourcePredicateSource)
+			}
+			if timeout, err := time.ParseDuration(action.Wait.Timeout); err != nil {
+				return fmt.Errorf("%s invalid wait timeout '%s': %s", index, action.Wait.Timeout, err.Error())
+			} else if timeout <= 0 {
+				return fmt.Errorf("%s wait timeout '%s' must be positive", index, action.Wait.Timeout)
+			}
+			if action.Wait.Interval != "" {
+				if interval, err := time.ParseDuration(action.Wait.Interval); err != nil {
+					return fmt.Errorf("%s invalid wait interval '%s': %s", index, action.Wait.Interval, err.Error())
+				} else if interval <= 0 {
+					return fmt.Errorf("%s wait interval '%s' must be positive", index, action.Wait.Interval)
+				}
+			}
+			var oldOnTimeout []models.Action
+			if old.Wait != nil {
+				oldOnTimeout = old.Wait.OnTimeout
+			}
+			if err := validateActionList(action.Wait.OnTimeout, oldOnTimeout, index+".onTimeout", true); err != nil {
+				return err
+			}
+		}
+		if action.If != nil {
+			if len(action.If.Then) == 0 && len(action.If.Else) == 0 {
+				return fmt.Errorf("%s if without then and else actions", index)
+			}
+			var oldThen, oldElse []models.Action
+			if old.If != nil {
+				oldThen, oldElse = old.If.Then, old.If.Else
+			}
+			if err := validateActionList(action.If.Then, oldThen, index+".then", true); err != nil {
+				return err
+			}
+			if err := validateActionList(action.If.Else, oldElse, index+".else", true); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// errActionSkipped is returned by the executors when the action does not need to be executed
+var errActionSkipped = errors.New("action skipped")
+
+func executeAction(ctx context.Context, index int, action models.Action, tc triggerContext) models.ActionResult {
+	ctx, cancel := actionContext(ctx, action)
+	defer cancel()
+
+	result := models.ActionResult{
+		Index:  index,
+		Type:   action.ActionType(),
+		Status: models.ActionSucceeded,
+	}
+
+	var err error
+	switch action.ActionType() {
+	case models.WebhookActionType:
+		err = executeWebhookAction(ctx, action.Webhook, tc)
+	case models.PublishActionType:
+		err = executePublishAction(ctx, action.Publish, tc)
+	case models.ScenarioActionType:
+		err = executeScenarioAction(ctx, action.Scenario, tc)
+	case models.SnapshotActionType:
+		err = executeSnapshotAction(ctx, action.Snapshot, tc)
+	case models.RestoreActionType:
+		err = executeRestoreAction(ctx, action.Restore, tc)
+	case models.RampActionType:
+		err = executeRampAction(ctx, action.Ramp, durationOrDefault(action.Timeout, actionTimeout), tc)
+	case models.IfActionType:
+		err = executeIfAction(ctx, action.If, tc, &result)
+	case models.WaitActionType:
+		err = executeWaitAction(ctx, action.Wait, tc, &result)
+	default:
+		if action.Target != nil {
+			err = executeTargetCommandAction(ctx, action, tc, &result)
+		} else {
+			err = executeCommandAction(ctx, action, tc)
+		}
+	}
+
+	if errors.Is(err, errActionSkipped) {
+		result.Status = models.ActionSkipped
+		if err != errActionSkipped {
+			result.Error = err.Error()
+		}
+	} else if err != nil {
+		result.Status = models.ActionFailed
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func executeCommandAction(ctx context.Context, action models.Action, tc triggerContext) error {
+	bodyParam, err := parseBody(action.Body)
+	if err != nil {
+		return fmt.Errorf("parse content error: %s", err.Error())
+	}
+
+	if action.Guard != nil {
+		if ok, err := checkCommandGuard(ctx, tc, action.Remote, action.DeviceName, action.Guard, bodyParam); err != nil {
+			return err
+		} else if ok {
+			return errActionSkipped
+		}
+	}
+
+	write := commandWrite{remote: action.Remote, deviceName: action.DeviceName, commandName: action.CommandName, params: bodyParam}
+	if other, ok := arbitrate(tc.RuleId, tc.priority, write); ok {
+		return fmt.Errorf("%w: overridden by active rule '%s' with priority %d", errActionSkipped, other.Name, other.PriorityValue())
+	}
+
+	if err = tc.executor.setCommand(ctx, action.Remote, action.DeviceName, action.CommandName, bodyParam); err != nil {
+		return err
+	}
+
+	if action.Verify != nil {
+		return verifyCommandAction(ctx, tc, action.Remote, action.DeviceName, action.Verify)
+	}
+	return nil
+}
+
+// deviceRemotes returns the remote core-commands of the devices read or written by the snapshot,
+// ramp, if and wait actions, the remote of the command actions is checked with their body
+func deviceRemotes(action models.Action) []string {
+	remotes := make([]string, 0)
+	switch {
+	case action.Snapshot != nil:
+		for _, resource := range action.Snapshot.Resources {
+			remotes = append(remotes, resource.Remote)
+		}
+	case action.Ramp != nil:
+		remotes = append(remotes, action.Ramp.Remote)
+	case action.If != nil:
+		remotes = append(remotes, action.If.Predicate.Remote)
+	case action.Wait != nil:
+		remotes = append(remotes, action.Wait.Predicate.Remote)
+	}
+	return remotes
+}
+
+// commandClientFor returns the client of the remote core-command with the name,
+// or the client of the local core-command if the name is empty
+func commandClientFor(remote string) (interfaces.CommandClient, error) {
+	if remote == "" {
+		return commandClient, nil
+	}
+	cc, ok := remoteCommandClients[remote]
+	if !ok {
+		return nil, fmt.Errorf("remote core-command '%s' is not configured", remote)
+	}
+	return cc, nil
+}
+
+// checkCommandGuard returns true if the resource of the device already has the target value,
+// the target value is taken from the body if the guard does not specify it
+func checkCommandGuard(ctx context.Context, tc triggerContext, remote string, deviceName string, guard *models.Guard, bodyParam map[string]string) (bool, error) {
+	target := guard.Value
+	if target == "" {
+		var ok bool
+		if target, ok = bodyParam[guard.ResourceName]; !ok {
+			return false, fmt.Errorf("guard error: no value of resource '%s' in the body", guard.ResourceName)
+		}
+	}
+
+	value, err := tc.executor.readResource(ctx, remote, deviceName, guard.ResourceName)
+	if err != nil {
+		return false, fmt.Errorf("guard error: %s", err.Error())
+	}
+	return equalValues(value, target), nil
+}
+
+// verifyCommandAction reads back the resource because a successful set command only means
+// that core-command accepted it, not that the device changed
+func verifyCommandAction(ctx context.Context, tc triggerContext, remote string, deviceName string, verify *models.Verification) error {
+	// the simulated readings only change with the commands, there is nothing to wait for
+	if verify.Delay != "" && !tc.executor.simulated() {
+		delay, err := time.ParseDuration(verify.Delay)
+		if err != nil {
+			return fmt.Errorf("parse verify delay '%s' error: %s", verify.Delay, err.Error())
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	value, err := tc.executor.readResource(ctx, remote, deviceName, verify.ResourceName)
+	if err != nil {
+		return fmt.Errorf("verify error: %s", err.Error())
+	}
+	if !equalValues(value, verify.Expected) {
+		return fmt.Errorf("verify failed: resource '%s' of device '%s' is '%s', expected '%s'", verify.ResourceName, deviceName, value, verify.Expected)
+	}
+	return nil
+}
+
+func executeWebhookAction(ctx context.Context, webhook *models.WebhookAction, tc triggerContext) error {
+	if webhook == nil {
+		return fmt.Errorf("no webhook specified")
+	}
+
+	var timeout time.Duration
+	if webhook.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(webhook.Timeout); err != nil {
+			return fmt.Errorf("parse timeout '%s' error: %s", webhook.Timeout, err.Error())
+		}
+	}
+
+	body, err := renderTemplate(webhook.Body, tc)
+	if err != nil {
+		return fmt.Errorf("render body error: %s", err.Error())
+	}
+
+	return tc.executor.callWebhook(ctx, webhook, []byte(body), timeout)
+}
+
+func executePublishAction(ctx context.Context, publish *models.PublishAction, tc triggerContext) error {
+	if publish == nil {
+		return fmt.Errorf("no publish specified")
+	}
+	topic, err := renderTemplate(publish.Topic, tc)
+	if err != nil {
+		return fmt.Errorf("render topic error: %s", err.Error())
+	}
+	payload, err := renderTemplate(publish.Payload, tc)
+	if err != nil {
+		return fmt.Errorf("render payload error: %s", err.Error())
+	}
+
+	return tc.executor.publish(ctx, topic, []byte(payload), publish.Qos, publish.Retained)
+}
+
+func renderTemplate(text string, data interface{}) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	tmpl, err := template.New("").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
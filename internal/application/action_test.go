@@ -0,0 +1,352 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/interfaces"
+
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+func TestExecuteWebhookAction(t *testing.T) {
+	tests := []struct {
+		name        string
+		webhook     models.WebhookAction
+		status      int
+		delay       time.Duration
+		wantBody    string
+		errContains string
+	}{
+		{
+			name: "rendered body",
+			webhook: models.WebhookAction{
+				Method:  http.MethodPut,
+				Headers: map[string]string{"X-Token": "secret"},
+				Body:    `{"rule":"{{.RuleName}}","condition":{{.TriggerIndex}},"state":{{.TriggerState}}}`,
+			},
+			status:   http.StatusOK,
+			wantBody: `{"rule":"overheat","condition":1,"state":true}`,
+		},
+		{
+			name:        "non-2xx status code",
+			webhook:     models.WebhookAction{Body: "{}"},
+			status:      http.StatusInternalServerError,
+			errContains: "unexpected status code: 500",
+		},
+		{
+			name:    "expected status code",
+			webhook: models.WebhookAction{ExpectedStatusCodes: []int{http.StatusNotFound}},
+			status:  http.StatusNotFound,
+		},
+		{
+			name:        "2xx status code not expected",
+			webhook:     models.WebhookAction{ExpectedStatusCodes: []int{http.StatusCreated}},
+			status:      http.StatusOK,
+			errContains: "unexpected status code: 200",
+		},
+		{
+			name:        "timeout",
+			webhook:     models.WebhookAction{Timeout: "50ms"},
+			status:      http.StatusOK,
+			delay:       5 * time.Second,
+			errContains: "failed to send a http request",
+		},
+		{
+			name:        "invalid timeout",
+			webhook:     models.WebhookAction{Timeout: "soon"},
+			errContains: "parse timeout 'soon' error",
+		},
+		{
+			name:        "invalid template",
+			webhook:     models.WebhookAction{Body: "{{.RuleName"},
+			status:      http.StatusOK,
+			errContains: "render body error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotMethod, gotToken, gotBody string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotMethod = r.Method
+				gotToken = r.Header.Get("X-Token")
+				body, _ := ioutil.ReadAll(r.Body)
+				gotBody = string(body)
+				select {
+				case <-time.After(tt.delay):
+				case <-r.Context().Done():
+					return
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			webhook := tt.webhook
+			webhook.Url = server.URL
+			tc := triggerContext{RuleId: "b4e4e2f6", RuleName: "overheat", TriggerIndex: 1, TriggerState: true, executor: liveExecutor{}}
+			started := time.Now()
+			err := executeWebhookAction(context.Background(), &webhook, tc)
+
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("error = %v, want an error containing %q", err, tt.errContains)
+				}
+				if tt.delay > 0 && time.Since(started) >= tt.delay {
+					t.Fatalf("webhook was not cancelled after its timeout")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantBody != "" && gotBody != tt.wantBody {
+				t.Errorf("body = %s, want %s", gotBody, tt.wantBody)
+			}
+			if webhook.Method != "" && gotMethod != webhook.Method {
+				t.Errorf("method = %s, want %s", gotMethod, webhook.Method)
+			}
+			if token := webhook.Headers["X-Token"]; gotToken != token {
+				t.Errorf("header X-Token = %s, want %s", gotToken, token)
+			}
+		})
+	}
+}
+
+func TestExecuteWebhookActionDefaultMethod(t *testing.T) {
+	var gotMethod string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+	}))
+	defer server.Close()
+
+	err := executeWebhookAction(context.Background(), &models.WebhookAction{Url: server.URL}, triggerContext{RuleName: "overheat", executor: liveExecutor{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotMethod != http.MethodPost {
+		t.Errorf("method = %s, want %s", gotMethod, http.MethodPost)
+	}
+}
+
+type publishedMessage struct {
+	topic    string
+	payload  string
+	qos      int
+	retained bool
+}
+
+// recordingMessageBus records the published messages instead of sending them
+type recordingMessageBus struct {
+	published []publishedMessage
+	err       error
+}
+
+func (c *recordingMessageBus) Publish(ctx context.Context, topic string, payload []byte, qos int, retained bool) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.published = append(c.published, publishedMessage{topic: topic, payload: string(payload), qos: qos, retained: retained})
+	return nil
+}
+
+func (c *recordingMessageBus) Disconnect() error {
+	return nil
+}
+
+func TestExecutePublishAction(t *testing.T) {
+	tests := []struct {
+		name        string
+		publish     models.PublishAction
+		publishErr  error
+		want        publishedMessage
+		errContains string
+	}{
+		{
+			name: "rendered topic and payload",
+			publish: models.PublishAction{
+				Topic:    "scenario/{{.RuleName}}",
+				Payload:  `{"condition":{{.TriggerIndex}},"state":{{.TriggerState}}}`,
+				Qos:      1,
+				Retained: true,
+			},
+			want: publishedMessage{
+				topic:    "scenario/overheat",
+				payload:  `{"condition":1,"state":true}`,
+				qos:      1,
+				retained: true,
+			},
+		},
+		{
+			name:    "empty payload",
+			publish: models.PublishAction{Topic: "scenario/alarm"},
+			want:    publishedMessage{topic: "scenario/alarm"},
+		},
+		{
+			name:        "invalid topic template",
+			publish:     models.PublishAction{Topic: "scenario/{{.RuleName"},
+			errContains: "render topic error",
+		},
+		{
+			name:        "invalid payload template",
+			publish:     models.PublishAction{Topic: "scenario/alarm", Payload: "{{.Unknown.Field}}"},
+			errContains: "render payload error",
+		},
+		{
+			name:        "message bus error",
+			publish:     models.PublishAction{Topic: "scenario/alarm"},
+			publishErr:  errors.New("broker unreachable"),
+			errContains: "broker unreachable",
+		},
+	}
+
+	defer func() { messageBusClient = nil }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &recordingMessageBus{err: tt.publishErr}
+			messageBusClient = bus
+
+			tc := triggerContext{RuleName: "overheat", TriggerIndex: 1, TriggerState: true, executor: liveExecutor{}}
+			err := executePublishAction(context.Background(), &tt.publish, tc)
+
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("error = %v, want an error containing %q", err, tt.errContains)
+				}
+				if len(bus.published) != 0 {
+					t.Fatalf("%d messages published, want none", len(bus.published))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(bus.published) != 1 || bus.published[0] != tt.want {
+				t.Fatalf("published = %+v, want [%+v]", bus.published, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutePublishActionWithoutMessageBus(t *testing.T) {
+	messageBusClient = nil
+	err := executePublishAction(context.Background(), &models.PublishAction{Topic: "scenario/alarm"}, triggerContext{executor: liveExecutor{}})
+	if err == nil || !strings.Contains(err.Error(), "message bus is not configured") {
+		t.Fatalf("error = %v, want message bus is not configured", err)
+	}
+}
+
+func TestValidateActionsOnlyChangedActions(t *testing.T) {
+	invalid := models.Action{Webhook: &models.WebhookAction{Url: "http://localhost/alarm", Timeout: "soon"}}
+	valid := models.Action{Webhook: &models.WebhookAction{Url: "http://localhost/alarm", Timeout: "1s"}}
+
+	tests := []struct {
+		name       string
+		actions    []models.Action
+		oldActions []models.Action
+		wantErr    string
+	}{
+		{"new rule", []models.Action{valid, invalid}, nil, "action[1] invalid webhook timeout 'soon'"},
+		{"unchanged action", []models.Action{valid, invalid}, []models.Action{valid, invalid}, ""},
+		{"changed action", []models.Action{invalid, invalid}, []models.Action{valid, invalid}, "action[0] invalid webhook timeout 'soon'"},
+		{"appended action", []models.Action{invalid, invalid}, []models.Action{invalid}, "action[1] invalid webhook timeout 'soon'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateActions(models.Rule{Name: "alarm", Actions: tt.actions}, tt.oldActions)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRemoteDevices(t *testing.T) {
+	local, remote := newFakeCoreCommand(), newFakeCoreCommand()
+	local.setValue("dimmer", "level", "10")
+	remote.setValue("dimmer", "level", "70")
+	commandClient = local
+	remoteCommandClients = map[string]interfaces.CommandClient{"site-b": remote}
+	defer func() {
+		commandClient = nil
+		remoteCommandClients = nil
+	}()
+	loadRules(t, models.Rule{Id: "sunrise", Name: "sunrise"})
+	tc := triggerContext{RuleId: "sunrise", RuleName: "sunrise", executor: liveExecutor{}}
+
+	predicate := models.Predicate{Source: models.ResourcePredicateSource, Remote: "site-b", DeviceName: "dimmer", ResourceName: "level", Operator: ">", Value: "50"}
+	if value, ok, err := evaluatePredicate(context.Background(), predicate, tc); err != nil || !ok || value != "70" {
+		t.Errorf("evaluatePredicate() = %s, %v, %v, want the remote value 70", value, ok, err)
+	}
+
+	snapshot := &models.SnapshotAction{Name: "remote-dimmer", Resources: []models.SnapshotResource{{Remote: "site-b", DeviceName: "dimmer", ResourceName: "level"}}}
+	if err := executeSnapshotAction(context.Background(), snapshot, tc); err != nil {
+		t.Fatalf("snapshot unexpected error: %v", err)
+	}
+	remote.setValue("dimmer", "level", "0")
+	if err := executeRestoreAction(context.Background(), &models.RestoreAction{Name: "remote-dimmer", Delete: true}, tc); err != nil {
+		t.Fatalf("restore unexpected error: %v", err)
+	}
+	if sets := remote.received(); len(sets) != 1 || sets[0].settings["level"] != "70" {
+		t.Errorf("remote writes %+v, want the restored level 70", sets)
+	}
+
+	ramp := &models.RampAction{Remote: "site-b", DeviceName: "dimmer", CommandName: "level", End: 90, Duration: "20ms", Steps: 2}
+	if err := executeRampAction(context.Background(), ramp, actionTimeout, tc); err != nil {
+		t.Fatalf("ramp unexpected error: %v", err)
+	}
+	// the ramp starts from the remote value 70 restored above
+	sets := waitForSets(t, remote, 3)
+	if sets[1].settings["level"] != "80" || sets[2].settings["level"] != "90" {
+		t.Errorf("remote ramp writes %+v, want 80 then 90", sets[1:])
+	}
+	if sets := local.received(); len(sets) != 0 {
+		t.Errorf("local writes %+v, want none", sets)
+	}
+}
+
+func TestValidateActionsRemotes(t *testing.T) {
+	remoteCommandClients = map[string]interfaces.CommandClient{"site-b": newFakeCoreCommand()}
+	defer func() { remoteCommandClients = nil }()
+
+	predicate := models.Predicate{Source: models.ResourcePredicateSource, Remote: "site-c", DeviceName: "door", ResourceName: "position", Operator: "=", Value: "closed"}
+	lamp := models.Action{Webhook: &models.WebhookAction{Url: "http://localhost/alarm"}}
+	tests := []struct {
+		name    string
+		action  models.Action
+		wantErr string
+	}{
+		{"ramp", models.Action{Ramp: &models.RampAction{Remote: "site-b", DeviceName: "dimmer", CommandName: "level", Duration: "1m", Steps: 10}}, ""},
+		{"unknown ramp remote", models.Action{Ramp: &models.RampAction{Remote: "site-c", DeviceName: "dimmer", CommandName: "level", Duration: "1m", Steps: 10}}, "action[0] remote core-command 'site-c' is not configured"},
+		{"unknown snapshot remote", models.Action{Snapshot: &models.SnapshotAction{Name: "s", Resources: []models.SnapshotResource{{Remote: "site-c", DeviceName: "dimmer", ResourceName: "level"}}}}, "action[0] remote core-command 'site-c' is not configured"},
+		{"unknown if remote", models.Action{If: &models.IfAction{Predicate: predicate, Then: []models.Action{lamp}}}, "action[0] remote core-command 'site-c' is not configured"},
+		{"unknown wait remote", models.Action{Wait: &models.WaitAction{Predicate: predicate, Timeout: "1m"}}, "action[0] remote core-command 'site-c' is not configured"},
+		{"trigger predicate remote", models.Action{If: &models.IfAction{Predicate: models.Predicate{Source: models.TriggerPredicateSource, Remote: "site-b", Operator: ">", Value: "30"}, Then: []models.Action{lamp}}}, "action[0] remote is only supported by resource predicates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateActions(models.Rule{Name: "remote", Actions: []models.Action{tt.action}}, nil)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
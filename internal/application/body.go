@@ -0,0 +1,255 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/responses"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// decodeBody decodes the body of a command action, the numbers are kept as json.Number
+// so that no precision is lost
+func decodeBody(body models.ActionBody) (map[string]interface{}, error) {
+	var params map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(body)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&params); err != nil {
+		return nil, err
+	}
+
+	if len(params) == 0 {
+		return nil, fmt.Errorf("no parameters specified")
+	}
+	return params, nil
+}
+
+// parseBody returns the parameters of the set command in the string format expected by core-command
+func parseBody(body models.ActionBody) (map[string]string, error) {
+	params, err := decodeBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	paramMap := make(map[string]string, len(params))
+	for name, value := range params {
+		str, err := formatBodyValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("parameter '%s': %s", name, err.Error())
+		}
+		paramMap[name] = str
+	}
+	return paramMap, nil
+}
+
+// formatBodyValue converts a JSON value to the string parsed by the device services:
+// numbers keep their original text, arrays and objects are JSON encoded
+func formatBodyValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "", fmt.Errorf("null value is not supported")
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+// validateCommandBody checks the parameters of the body against the value types of the resources
+// in the profile of the device. The check is skipped if core-metadata can not be reached
+func validateCommandBody(action models.Action) error {
+	params, err := decodeBody(action.Body)
+	if err != nil {
+		return fmt.Errorf("invalid body: %s", err.Error())
+	}
+
+	profileName, err := commandProfileName(action)
+	if err != nil || profileName == "" {
+		return err
+	}
+
+	var response responses.DeviceProfileResponse
+	err = requestWithTimeout(func(ctx context.Context) error {
+		var edgexErr errors.EdgeX
+		response, edgexErr = metadataClient.DeviceProfileByName(ctx, profileName)
+		return edgexErr
+	})
+	if err != nil {
+		if errors.Kind(err) == errors.KindEntityDoesNotExist {
+			return fmt.Errorf("profile '%s' does not exists", profileName)
+		}
+		lc.Warnf("body of command '%s' not validated: query profile '%s' error: %s", action.CommandName, profileName, err.Error())
+		return nil
+	}
+
+	resources, err := commandResources(response.Profile, action.CommandName)
+	if err != nil {
+		return err
+	}
+	for name, value := range params {
+		resource, ok := resources[name]
+		if !ok {
+			return fmt.Errorf("resource '%s' is not part of command '%s' of profile '%s'", name, action.CommandName, profileName)
+		}
+		if !strings.Contains(resource.Properties.ReadWrite, common.ReadWrite_W) {
+			return fmt.Errorf("resource '%s' of profile '%s' is not writable", name, profileName)
+		}
+		if err = checkValueType(value, resource.Properties.ValueType); err != nil {
+			return fmt.Errorf("parameter '%s': %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+// commandProfileName returns the profile of the device of the action, or the profile of the target.
+// It returns an empty name if the profile can not be known at creation
+func commandProfileName(action models.Action) (string, error) {
+	if action.Target != nil {
+		return action.Target.ProfileName, nil
+	}
+
+	var response responses.DeviceResponse
+	err := requestWithTimeout(func(ctx context.Context) error {
+		var edgexErr errors.EdgeX
+		response, edgexErr = metadataClient.DeviceByName(ctx, action.DeviceName)
+		return edgexErr
+	})
+	if err != nil {
+		if errors.Kind(err) == errors.KindEntityDoesNotExist {
+			return "", fmt.Errorf("device '%s' does not exists", action.DeviceName)
+		}
+		lc.Warnf("body of command '%s' not validated: query device '%s' error: %s", action.CommandName, action.DeviceName, err.Error())
+		return "", nil
+	}
+	return response.Device.ProfileName, nil
+}
+
+// commandResources returns the resources which can be set by the command, a command
+// is either a device command or a single device resource
+func commandResources(profile dtos.DeviceProfile, commandName string) (map[string]dtos.DeviceResource, error) {
+	allResources := make(map[string]dtos.DeviceResource, len(profile.DeviceResources))
+	for _, r := range profile.DeviceResources {
+		allResources[r.Name] = r
+	}
+
+	for _, c := range profile.DeviceCommands {
+		if c.Name != commandName {
+			continue
+		}
+		if !strings.Contains(c.ReadWrite, common.ReadWrite_W) {
+			return nil, fmt.Errorf("command '%s' of profile '%s' is not writable", commandName, profile.Name)
+		}
+		resources := make(map[string]dtos.DeviceResource, len(c.ResourceOperations))
+		for _, ro := range c.ResourceOperations {
+			if r, ok := allResources[ro.DeviceResource]; ok {
+				resources[r.Name] = r
+			}
+		}
+		return resources, nil
+	}
+
+	if r, ok := allResources[commandName]; ok {
+		return map[string]dtos.DeviceResource{r.Name: r}, nil
+	}
+	return nil, fmt.Errorf("command '%s' does not exists in profile '%s'", commandName, profile.Name)
+}
+
+// checkValueType checks that the JSON value can be converted to the value type of the resource,
+// the string values of the older bodies are accepted if they can be parsed
+func checkValueType(value interface{}, valueType string) error {
+	if strings.HasSuffix(valueType, "Array") {
+		elements, ok := value.([]interface{})
+		if !ok {
+			str, isStr := value.(string)
+			if !isStr {
+				return fmt.Errorf("%s expects an array", valueType)
+			}
+			decoder := json.NewDecoder(bytes.NewBufferString(str))
+			decoder.UseNumber()
+			if err := decoder.Decode(&elements); err != nil {
+				return fmt.Errorf("%s expects an array: %s", valueType, err.Error())
+			}
+		}
+		elementType := strings.TrimSuffix(valueType, "Array")
+		for i, e := range elements {
+			if err := checkValueType(e, elementType); err != nil {
+				return fmt.Errorf("element[%d]: %s", i, err.Error())
+			}
+		}
+		return nil
+	}
+
+	switch valueType {
+	case common.ValueTypeString:
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s expects a string", valueType)
+		}
+		return nil
+	case common.ValueTypeBool:
+		switch v := value.(type) {
+		case bool:
+			return nil
+		case string:
+			if _, err := strconv.ParseBool(v); err == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s expects a boolean", valueType)
+	case common.ValueTypeBinary:
+		return fmt.Errorf("%s resources can not be set by an action", valueType)
+	}
+
+	var str string
+	switch v := value.(type) {
+	case json.Number:
+		str = v.String()
+	case string:
+		str = v
+	default:
+		return fmt.Errorf("%s expects a number", valueType)
+	}
+
+	var err error
+	switch valueType {
+	case common.ValueTypeUint8:
+		_, err = strconv.ParseUint(str, 10, 8)
+	case common.ValueTypeUint16:
+		_, err = strconv.ParseUint(str, 10, 16)
+	case common.ValueTypeUint32:
+		_, err = strconv.ParseUint(str, 10, 32)
+	case common.ValueTypeUint64:
+		_, err = strconv.ParseUint(str, 10, 64)
+	case common.ValueTypeInt8:
+		_, err = strconv.ParseInt(str, 10, 8)
+	case common.ValueTypeInt16:
+		_, err = strconv.ParseInt(str, 10, 16)
+	case common.ValueTypeInt32:
+		_, err = strconv.ParseInt(str, 10, 32)
+	case common.ValueTypeInt64:
+		_, err = strconv.ParseInt(str, 10, 64)
+	case common.ValueTypeFloat32:
+		_, err = strconv.ParseFloat(str, 32)
+	case common.ValueTypeFloat64:
+		_, err = strconv.ParseFloat(str, 64)
+	}
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid %s", str, valueType)
+	}
+	return nil
+}
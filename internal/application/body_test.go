@@ -0,0 +1,126 @@
+package application
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+func TestDecodeBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    models.ActionBody
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "typed values",
+			body: `{"setpoint":21.50,"enabled":true,"mode":"eco","levels":[1,2]}`,
+			want: map[string]interface{}{
+				"setpoint": json.Number("21.50"),
+				"enabled":  true,
+				"mode":     "eco",
+				"levels":   []interface{}{json.Number("1"), json.Number("2")},
+			},
+		},
+		{
+			name: "large integer keeps its precision",
+			body: `{"counter":9007199254740993}`,
+			want: map[string]interface{}{"counter": json.Number("9007199254740993")},
+		},
+		{name: "empty body", body: "", wantErr: true},
+		{name: "no parameters", body: "{}", wantErr: true},
+		{name: "invalid JSON", body: `{"setpoint":`, wantErr: true},
+		{name: "not an object", body: `[1,2]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeBody(tt.body)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("decodeBody(%s) = %v, want an error", tt.body, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeBody(%s) = %#v, want %#v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBody(t *testing.T) {
+	got, err := parseBody(`{"setpoint":21.50,"enabled":false,"mode":"eco","levels":[1,2],"config":{"a":1}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"setpoint": "21.50",
+		"enabled":  "false",
+		"mode":     "eco",
+		"levels":   "[1,2]",
+		"config":   `{"a":1}`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBody() = %v, want %v", got, want)
+	}
+
+	if _, err = parseBody(`{"setpoint":null}`); err == nil || !strings.Contains(err.Error(), "setpoint") {
+		t.Errorf("parseBody() with a null value error = %v, want an error on setpoint", err)
+	}
+}
+
+func TestCheckValueType(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		valueType string
+		wantErr   bool
+	}{
+		{"string", "eco", common.ValueTypeString, false},
+		{"number for string", json.Number("1"), common.ValueTypeString, true},
+		{"bool", true, common.ValueTypeBool, false},
+		{"bool as string", "false", common.ValueTypeBool, false},
+		{"invalid bool string", "yes", common.ValueTypeBool, true},
+		{"number for bool", json.Number("1"), common.ValueTypeBool, true},
+		{"uint8", json.Number("255"), common.ValueTypeUint8, false},
+		{"uint8 overflow", json.Number("256"), common.ValueTypeUint8, true},
+		{"negative uint16", json.Number("-1"), common.ValueTypeUint16, true},
+		{"uint64 as string", "18446744073709551615", common.ValueTypeUint64, false},
+		{"int8 underflow", json.Number("-129"), common.ValueTypeInt8, true},
+		{"int32 float", json.Number("1.5"), common.ValueTypeInt32, true},
+		{"int64", json.Number("-9223372036854775808"), common.ValueTypeInt64, false},
+		{"float32", json.Number("21.5"), common.ValueTypeFloat32, false},
+		{"float64 exponent", json.Number("1e300"), common.ValueTypeFloat64, false},
+		{"float32 overflow", json.Number("1e300"), common.ValueTypeFloat32, true},
+		{"bool for number", true, common.ValueTypeFloat64, true},
+		{"text for number", "warm", common.ValueTypeInt16, true},
+		{"binary", "AAEC", common.ValueTypeBinary, true},
+		{"array", []interface{}{json.Number("1"), json.Number("2")}, common.ValueTypeInt8Array, false},
+		{"array element out of range", []interface{}{json.Number("1"), json.Number("300")}, common.ValueTypeUint8Array, true},
+		{"array as string", "[true,false]", common.ValueTypeBoolArray, false},
+		{"invalid array string", "[1,", common.ValueTypeFloat32Array, true},
+		{"scalar for array", json.Number("1"), common.ValueTypeInt32Array, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkValueType(tt.value, tt.valueType)
+			if tt.wantErr && err == nil {
+				t.Errorf("checkValueType(%v, %s) = nil, want an error", tt.value, tt.valueType)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("checkValueType(%v, %s) unexpected error: %v", tt.value, tt.valueType, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// executeIfAction evaluates the predicate and runs the actions of the branch,
+// the branch taken and the results of its actions are recorded in the result
+func executeIfAction(ctx context.Context, ifAction *models.IfAction, tc triggerContext, result *models.ActionResult) error {
+	if ifAction == nil {
+		return fmt.Errorf("no if specified")
+	}
+
+	value, ok, err := evaluatePredicate(ctx, ifAction.Predicate, tc)
+	result.Value = value
+	if err != nil {
+		return fmt.Errorf("predicate error: %s", err.Error())
+	}
+
+	actions := ifAction.Else
+	result.Branch = models.ElseBranch
+	if ok {
+		actions = ifAction.Then
+		result.Branch = models.ThenBranch
+	}
+	if len(actions) == 0 {
+		return errActionSkipped
+	}
+
+	arrError := make([]string, 0)
+	for index, action := range actions {
+		actionResult := executeAction(ctx, index, action, tc)
+		if actionResult.Status == models.ActionFailed {
+			arrError = append(arrError, fmt.Sprintf("%s[%d]: %s", result.Branch, index, actionResult.Error))
+		}
+		result.Actions = append(result.Actions, actionResult)
+		if stopsSequence(actionResult) {
+			break
+		}
+	}
+
+	if len(arrError) > 0 {
+		return fmt.Errorf("some actions errored: %s", strings.Join(arrError, "; "))
+	}
+	return nil
+}
+
+// evaluatePredicate returns the value tested by the predicate and the result of the comparison
+func evaluatePredicate(ctx context.Context, predicate models.Predicate, tc triggerContext) (string, bool, error) {
+	var value string
+	switch predicate.Source {
+	case models.TriggerPredicateSource:
+		if tc.Value == nil {
+			return "", false, fmt.Errorf("no trigger value")
+		}
+		var err error
+		if value, err = formatBodyValue(tc.Value); err != nil {
+			return "", false, err
+		}
+	case models.ResourcePredicateSource:
+		var err error
+		if value, err = tc.executor.readResource(ctx, predicate.Remote, predicate.DeviceName, predicate.ResourceName); err != nil {
+			return "", false, err
+		}
+	default:
+		return "", false, fmt.Errorf("unknown source '%s'", predicate.Source)
+	}
+
+	ok, err := compareValues(value, predicate.Operator, predicate.Value)
+	return value, ok, err
+}
@@ -0,0 +1,284 @@
+package application
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+
+	ctModels "github.com/edgexfoundry/go-mod-core-contracts/v2/models"
+
+	"github.com/rddigital/device-scenario/internal/cache"
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// commandWrite is the body of a command action sent to a device
+type commandWrite struct {
+	remote      string
+	deviceName  string
+	commandName string
+	params      map[string]string
+}
+
+// ruleCommandWrites returns the bodies of the command actions, including the actions of the branches.
+// The devices of the targets and the actions of the scenarios are not known statically and are ignored
+func ruleCommandWrites(actions []models.Action) []commandWrite {
+	writes := make([]commandWrite, 0)
+	for _, action := range actions {
+		switch {
+		case action.If != nil:
+			writes = append(writes, ruleCommandWrites(action.If.Then)...)
+			writes = append(writes, ruleCommandWrites(action.If.Else)...)
+		case action.Wait != nil:
+			writes = append(writes, ruleCommandWrites(action.Wait.OnTimeout)...)
+		case action.ActionType() == models.CommandActionType && action.Target == nil:
+			params, err := parseBody(action.Body)
+			if err != nil {
+				continue
+			}
+			writes = append(writes, commandWrite{
+				remote:      action.Remote,
+				deviceName:  action.DeviceName,
+				commandName: action.CommandName,
+				params:      params,
+			})
+		}
+	}
+	return writes
+}
+
+// key is the command written
+func (w commandWrite) key() string {
+	return w.remote + "/" + w.deviceName + "/" + w.commandName
+}
+
+// writeIndex holds the command writes of the rules, they are computed when the rules are added or
+// updated instead of parsing the bodies of all rules on each command
+type writeIndex struct {
+	byRule    map[string][]commandWrite            // key is rule id
+	byCommand map[string]map[string][]commandWrite // key is the command written, then rule id
+	mutex     sync.RWMutex
+}
+
+var (
+	ruleWrites = &writeIndex{
+		byRule:    make(map[string][]commandWrite),
+		byCommand: make(map[string]map[string][]commandWrite),
+	}
+)
+
+// set replaces the command writes of the rule
+func (wi *writeIndex) set(rule models.Rule) {
+	writes := ruleCommandWrites(rule.Actions)
+
+	wi.mutex.Lock()
+	defer wi.mutex.Unlock()
+
+	wi.remove(rule.Id)
+	wi.byRule[rule.Id] = writes
+	for _, w := range writes {
+		rules, ok := wi.byCommand[w.key()]
+		if !ok {
+			rules = make(map[string][]commandWrite)
+			wi.byCommand[w.key()] = rules
+		}
+		rules[rule.Id] = append(rules[rule.Id], w)
+	}
+}
+
+func (wi *writeIndex) delete(id string) {
+	wi.mutex.Lock()
+	defer wi.mutex.Unlock()
+
+	wi.remove(id)
+}
+
+// remove must be called with the lock held
+func (wi *writeIndex) remove(id string) {
+	for _, w := range wi.byRule[id] {
+		if rules, ok := wi.byCommand[w.key()]; ok {
+			delete(rules, id)
+			if len(rules) == 0 {
+				delete(wi.byCommand, w.key())
+			}
+		}
+	}
+	delete(wi.byRule, id)
+}
+
+func (wi *writeIndex) forRule(id string) []commandWrite {
+	wi.mutex.RLock()
+	defer wi.mutex.RUnlock()
+
+	return wi.byRule[id]
+}
+
+// forCommand returns the writes of the same command by rule id
+func (wi *writeIndex) forCommand(write commandWrite) map[string][]commandWrite {
+	wi.mutex.RLock()
+	defer wi.mutex.RUnlock()
+
+	rules := make(map[string][]commandWrite, len(wi.byCommand[write.key()]))
+	for id, writes := range wi.byCommand[write.key()] {
+		rules[id] = writes
+	}
+	return rules
+}
+
+// contradicts returns the first resource of the same command written with another value, and both values
+func (w commandWrite) contradicts(other commandWrite) (string, string, string, bool) {
+	if w.remote != other.remote || w.deviceName != other.deviceName || w.commandName != other.commandName {
+		return "", "", "", false
+	}
+	for name, value := range w.params {
+		if otherValue, ok := other.params[name]; ok && !equalValues(value, otherValue) {
+			return name, value, otherValue, true
+		}
+	}
+	return "", "", "", false
+}
+
+// detectConflicts returns a warning for each command of another rule which writes another value to a
+// resource written by the rule, unless the conditions of the rules can not be true at the same time
+func detectConflicts(rule models.Rule) []string {
+	warnings := make([]string, 0)
+	writes := ruleCommandWrites(rule.Actions)
+	if len(writes) == 0 {
+		return warnings
+	}
+
+	rules := cache.Rules().All()
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Name < rules[j].Name
+	})
+	for _, other := range rules {
+		if other.Id == rule.Id || conditionsExclusive(rule, other) {
+			continue
+		}
+		for _, ow := range ruleWrites.forRule(other.Id) {
+			for _, w := range writes {
+				if resource, value, otherValue, ok := w.contradicts(ow); ok {
+					warnings = append(warnings, fmt.Sprintf("rule '%s' (priority %d) may write '%s' to resource '%s' of command '%s' of device '%s' while this rule (priority %d) writes '%s'",
+						other.Name, other.PriorityValue(), otherValue, resource, w.commandName, w.deviceName, rule.PriorityValue(), value))
+				}
+			}
+		}
+	}
+	return warnings
+}
+
+// arbitrate returns the active rule with the highest priority above the priority of the rule which
+// writes another value to a resource of the command, the write is then overridden by this rule
+func arbitrate(ruleId string, priority int, write commandWrite) (models.Rule, bool) {
+	var winner models.Rule
+	found := false
+	for otherId, writes := range ruleWrites.forCommand(write) {
+		if otherId == ruleId {
+			continue
+		}
+		other, ok := cache.Rules().ForId(otherId)
+		if !ok || other.PriorityValue() <= priority || other.AdminState != ctModels.Unlocked {
+			continue
+		}
+		if found && other.PriorityValue() <= winner.PriorityValue() {
+			continue
+		}
+		for _, ow := range writes {
+			if _, _, _, ok := write.contradicts(ow); ok && checkRuleConditions(other.Id) {
+				winner = other
+				found = true
+				break
+			}
+		}
+	}
+	return winner, found
+}
+
+// conditionsExclusive returns true if both rules require a threshold condition on the same resource
+// and the ranges of the thresholds do not intersect
+func conditionsExclusive(a models.Rule, b models.Rule) bool {
+	for _, ca := range requiredThresholds(a) {
+		for _, cb := range requiredThresholds(b) {
+			if ca.DeviceThreshold != cb.DeviceThreshold || ca.ResourceThreshold != cb.ResourceThreshold {
+				continue
+			}
+			ra, okA := thresholdRange(ca)
+			rb, okB := thresholdRange(cb)
+			if okA && okB && !ra.intersects(rb) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requiredThresholds returns the threshold conditions which must be true for the rule to fire. The
+// conditions are combined from the first, so a condition is required if it and all the conditions
+// after it are combined with "and"
+func requiredThresholds(rule models.Rule) []models.Condition {
+	required := make([]models.Condition, 0)
+	index := len(rule.Conditions) - 1
+	for ; index > 0 && rule.Conditions[index].Logic == cm.AndLogic; index-- {
+		required = append(required, rule.Conditions[index])
+	}
+	if index == 0 && len(rule.Conditions) > 0 {
+		required = append(required, rule.Conditions[0])
+	}
+
+	thresholds := make([]models.Condition, 0, len(required))
+	for _, c := range required {
+		if c.Type == cm.ThresholdRuleType {
+			thresholds = append(thresholds, c)
+		}
+	}
+	return thresholds
+}
+
+// valueRange is the range of the values satisfying a threshold
+type valueRange struct {
+	low, high         float64
+	lowOpen, highOpen bool
+}
+
+func thresholdRange(c models.Condition) (valueRange, bool) {
+	value, err := strconv.ParseFloat(c.ValueThreshold, 64)
+	if err != nil {
+		return valueRange{}, false
+	}
+
+	r := valueRange{low: math.Inf(-1), high: math.Inf(1), lowOpen: true, highOpen: true}
+	switch c.OperatorThreshold {
+	case ">":
+		r.low = value
+	case ">=":
+		r.low, r.lowOpen = value, false
+	case "<":
+		r.high = value
+	case "<=":
+		r.high, r.highOpen = value, false
+	case "=":
+		r.low, r.lowOpen = value, false
+		r.high, r.highOpen = value, false
+	default:
+		return valueRange{}, false
+	}
+	return r, true
+}
+
+func (r valueRange) intersects(other valueRange) bool {
+	low, lowOpen := r.low, r.lowOpen
+	if other.low > low || (other.low == low && other.lowOpen) {
+		low, lowOpen = other.low, other.lowOpen
+	}
+	high, highOpen := r.high, r.highOpen
+	if other.high < high || (other.high == high && other.highOpen) {
+		high, highOpen = other.high, other.highOpen
+	}
+
+	if low != high {
+		return low < high
+	}
+	return !lowOpen && !highOpen
+}
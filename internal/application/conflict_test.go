@@ -0,0 +1,178 @@
+package application
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	ctModels "github.com/edgexfoundry/go-mod-core-contracts/v2/models"
+
+	"github.com/rddigital/device-scenario/internal/cache"
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// temperature returns a threshold condition on the temperature of the device
+func temperature(logic string, deviceName string, operator string, value string) models.Condition {
+	return models.Condition{
+		Logic:             logic,
+		Type:              cm.ThresholdRuleType,
+		DeviceThreshold:   deviceName,
+		ResourceThreshold: "temperature",
+		OperatorThreshold: operator,
+		ValueThreshold:    value,
+	}
+}
+
+func TestRequiredThresholds(t *testing.T) {
+	hot := temperature(cm.AndLogic, "thermostat", ">", "30")
+	humid := models.Condition{Logic: cm.AndLogic, Type: cm.ThresholdRuleType, DeviceThreshold: "hygrometer", ResourceThreshold: "humidity", OperatorThreshold: ">", ValueThreshold: "80"}
+	hourly := models.Condition{Logic: cm.AndLogic, Type: cm.ScheduleRuleType, IntervalTime: "1h"}
+	orHumid, orHourly := humid, hourly
+	orHumid.Logic, orHourly.Logic = cm.OrLogic, cm.OrLogic
+
+	tests := []struct {
+		name       string
+		conditions []models.Condition
+		want       []models.Condition
+	}{
+		{"no condition", nil, []models.Condition{}},
+		{"single threshold", []models.Condition{hot}, []models.Condition{hot}},
+		{"schedule is not a threshold", []models.Condition{hourly}, []models.Condition{}},
+		{"and", []models.Condition{hot, humid}, []models.Condition{humid, hot}},
+		{"or after the threshold", []models.Condition{hot, orHumid}, []models.Condition{}},
+		{"and after an or", []models.Condition{hot, orHumid, hourly}, []models.Condition{}},
+		{"threshold and after an or", []models.Condition{hourly, orHumid, hot}, []models.Condition{hot}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := requiredThresholds(models.Rule{Conditions: tt.conditions})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requiredThresholds() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionsExclusive(t *testing.T) {
+	tests := []struct {
+		name string
+		a    models.Condition
+		b    models.Condition
+		want bool
+	}{
+		{"disjoint ranges", temperature(cm.AndLogic, "thermostat", ">", "30"), temperature(cm.AndLogic, "thermostat", "<", "20"), true},
+		{"overlapping ranges", temperature(cm.AndLogic, "thermostat", ">", "20"), temperature(cm.AndLogic, "thermostat", "<", "30"), false},
+		{"open bound at the same value", temperature(cm.AndLogic, "thermostat", ">", "25"), temperature(cm.AndLogic, "thermostat", "<=", "25"), true},
+		{"closed bounds at the same value", temperature(cm.AndLogic, "thermostat", ">=", "25"), temperature(cm.AndLogic, "thermostat", "<=", "25"), false},
+		{"different values", temperature(cm.AndLogic, "thermostat", "=", "25"), temperature(cm.AndLogic, "thermostat", "=", "26"), true},
+		{"different devices", temperature(cm.AndLogic, "thermostat", ">", "30"), temperature(cm.AndLogic, "boiler", "<", "20"), false},
+		{"not a number", temperature(cm.AndLogic, "thermostat", "=", "high"), temperature(cm.AndLogic, "thermostat", "=", "low"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := models.Rule{Conditions: []models.Condition{tt.a}}
+			b := models.Rule{Conditions: []models.Condition{tt.b}}
+			if got := conditionsExclusive(a, b); got != tt.want {
+				t.Errorf("conditionsExclusive() = %v, want %v", got, tt.want)
+			}
+			if got := conditionsExclusive(b, a); got != tt.want {
+				t.Errorf("conditionsExclusive() swapped = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// the threshold is not required when it is or-ed with a schedule
+	a := models.Rule{Conditions: []models.Condition{
+		temperature(cm.AndLogic, "thermostat", ">", "30"),
+		{Logic: cm.OrLogic, Type: cm.ScheduleRuleType, IntervalTime: "1h"},
+	}}
+	b := models.Rule{Conditions: []models.Condition{temperature(cm.AndLogic, "thermostat", "<", "20")}}
+	if conditionsExclusive(a, b) {
+		t.Errorf("conditionsExclusive() with an optional threshold = true, want false")
+	}
+}
+
+func TestDetectConflicts(t *testing.T) {
+	five, nine := 5, 9
+	cooling := models.Rule{
+		Id: "cooling", Name: "cooling", Priority: &five,
+		Conditions: []models.Condition{temperature(cm.AndLogic, "thermostat", ">", "30")},
+		Actions:    []models.Action{{DeviceName: "fan", CommandName: "speed", Body: `{"speed":3}`}},
+	}
+	night := models.Rule{
+		Id: "night", Name: "night", Priority: &nine,
+		Conditions: []models.Condition{{Logic: cm.AndLogic, Type: cm.ScheduleRuleType, IntervalTime: "1h"}},
+		Actions:    []models.Action{{DeviceName: "fan", CommandName: "speed", Body: `{"speed":1}`}},
+	}
+	heating := models.Rule{
+		Id: "heating", Name: "heating",
+		Conditions: []models.Condition{temperature(cm.AndLogic, "thermostat", "<", "18")},
+		Actions:    []models.Action{{DeviceName: "fan", CommandName: "speed", Body: `{"speed":0}`}},
+	}
+	loadRules(t, cooling, night, heating)
+
+	warnings := detectConflicts(cooling)
+	if len(warnings) != 1 {
+		t.Fatalf("warnings = %q, want one warning about the night rule", warnings)
+	}
+	want := "rule 'night' (priority 9) may write '1' to resource 'speed' of command 'speed' of device 'fan' while this rule (priority 5) writes '3'"
+	if warnings[0] != want {
+		t.Errorf("warning = %q, want %q", warnings[0], want)
+	}
+	for _, w := range warnings {
+		if strings.Contains(w, "heating") {
+			t.Errorf("warning about the heating rule whose conditions are exclusive: %q", w)
+		}
+	}
+}
+
+func TestArbitrate(t *testing.T) {
+	fan := func(id string, priority int, adminState ctModels.AdminState, body models.ActionBody) models.Rule {
+		return models.Rule{
+			Id:         id,
+			Name:       id,
+			AdminState: adminState,
+			Priority:   &priority,
+			Conditions: []models.Condition{temperature(cm.AndLogic, "thermostat", ">", "30")},
+			Actions:    []models.Action{{DeviceName: "fan", CommandName: "speed", Body: body}},
+		}
+	}
+	write := commandWrite{deviceName: "fan", commandName: "speed", params: map[string]string{"speed": "1"}}
+
+	tests := []struct {
+		name       string
+		rules      []models.Rule
+		active     []string
+		wantWinner string
+	}{
+		{"higher priority active rule", []models.Rule{fan("boost", 5, ctModels.Unlocked, `{"speed":3}`)}, []string{"boost"}, "boost"},
+		{"higher priority rule not active", []models.Rule{fan("boost", 5, ctModels.Unlocked, `{"speed":3}`)}, nil, ""},
+		{"lower priority rule", []models.Rule{fan("quiet", 0, ctModels.Unlocked, `{"speed":3}`)}, []string{"quiet"}, ""},
+		{"locked rule", []models.Rule{fan("boost", 5, ctModels.Locked, `{"speed":3}`)}, []string{"boost"}, ""},
+		{"same value", []models.Rule{fan("boost", 5, ctModels.Unlocked, `{"speed":1.0}`)}, []string{"boost"}, ""},
+		{"other resource", []models.Rule{fan("boost", 5, ctModels.Unlocked, `{"mode":"eco"}`)}, []string{"boost"}, ""},
+		{
+			name:       "highest priority wins",
+			rules:      []models.Rule{fan("boost", 5, ctModels.Unlocked, `{"speed":3}`), fan("storm", 9, ctModels.Unlocked, `{"speed":2}`)},
+			active:     []string{"boost", "storm"},
+			wantWinner: "storm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loadRules(t, tt.rules...)
+			for _, id := range tt.active {
+				cache.Rules().UpdateStateRule(id, 0, true, "thermostat")
+			}
+
+			winner, found := arbitrate("eco", 1, write)
+			if found != (tt.wantWinner != "") || winner.Id != tt.wantWinner {
+				t.Errorf("arbitrate() = '%s', %v, want '%s'", winner.Id, found, tt.wantWinner)
+			}
+		})
+	}
+}
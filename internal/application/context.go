@@ -0,0 +1,103 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+type executionManager struct {
+	cancels map[string]map[int64]context.CancelFunc // key is rule id, then execution id
+	nextId  int64
+	mutex   sync.Mutex
+}
+
+var (
+	executions = &executionManager{
+		cancels: make(map[string]map[int64]context.CancelFunc),
+	}
+)
+
+// startExecution returns the context of an execution of the actions of the rule, the context expires
+// after the timeout of the rule and it is cancelled when the rule is deleted or locked.
+// The returned function must be called when the execution ends
+func startExecution(rule models.Rule) (context.Context, func()) {
+	timeout := durationOrDefault(rule.Timeout, ruleTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+	executions.mutex.Lock()
+	executions.nextId++
+	id := executions.nextId
+	if executions.cancels[rule.Id] == nil {
+		executions.cancels[rule.Id] = make(map[int64]context.CancelFunc)
+	}
+	executions.cancels[rule.Id][id] = cancel
+	executions.mutex.Unlock()
+
+	return ctx, func() {
+		executions.mutex.Lock()
+		delete(executions.cancels[rule.Id], id)
+		if len(executions.cancels[rule.Id]) == 0 {
+			delete(executions.cancels, rule.Id)
+		}
+		executions.mutex.Unlock()
+		cancel()
+	}
+}
+
+// cancelRuleExecutions cancels the executions of the rule which are still running
+func cancelRuleExecutions(ruleId string) {
+	executions.mutex.Lock()
+	defer executions.mutex.Unlock()
+
+	for _, cancel := range executions.cancels[ruleId] {
+		cancel()
+	}
+	if n := len(executions.cancels[ruleId]); n > 0 {
+		lc.Debugf("%d running executions of rule with id '%s' cancelled", n, ruleId)
+	}
+	delete(executions.cancels, ruleId)
+}
+
+// actionContext returns the context of the action with its deadline, the actions running other
+// actions only have a deadline if it is set in the action
+func actionContext(ctx context.Context, action models.Action) (context.Context, context.CancelFunc) {
+	var timeout time.Duration
+	switch action.ActionType() {
+	case models.IfActionType, models.WaitActionType, models.ScenarioActionType:
+		timeout = durationOrDefault(action.Timeout, 0)
+	default:
+		timeout = durationOrDefault(action.Timeout, actionTimeout)
+	}
+
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// requestContext returns the context of a request to another service outside the execution of actions
+func requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), requestTimeout)
+}
+
+// requestWithTimeout calls a service outside the execution of actions, the request is cancelled after the request timeout
+func requestWithTimeout(call func(ctx context.Context) error) error {
+	ctx, cancel := requestContext()
+	defer cancel()
+	return call(ctx)
+}
+
+// durationOrDefault parses the duration, the default value is returned if it is empty or invalid
+func durationOrDefault(value string, defaultValue time.Duration) time.Duration {
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return d
+}
@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	dtoCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/responses"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+)
+
+// setCommand is a write received by fakeCoreCommand
+type setCommand struct {
+	deviceName  string
+	commandName string
+	settings    map[string]string
+}
+
+// fakeCoreCommand stands for core-command and the devices behind it: a write sets the values of the
+// resources, a read returns them. The calls to the devices in hanging block until their context is done,
+// answer ends the hanging of a device for the next calls
+type fakeCoreCommand struct {
+	values  map[string]map[string]string // key is device name, then resource name
+	hanging map[string]bool
+	sets    []setCommand
+	gets    int
+	mutex   sync.Mutex
+}
+
+func newFakeCoreCommand() *fakeCoreCommand {
+	return &fakeCoreCommand{
+		values:  make(map[string]map[string]string),
+		hanging: make(map[string]bool),
+	}
+}
+
+func (c *fakeCoreCommand) setValue(deviceName string, resourceName string, value string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.values[deviceName] == nil {
+		c.values[deviceName] = make(map[string]string)
+	}
+	c.values[deviceName][resourceName] = value
+}
+
+func (c *fakeCoreCommand) hang(deviceName string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.hanging[deviceName] = true
+}
+
+func (c *fakeCoreCommand) answer(deviceName string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.hanging, deviceName)
+}
+
+func (c *fakeCoreCommand) received() []setCommand {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]setCommand(nil), c.sets...)
+}
+
+func (c *fakeCoreCommand) wait(ctx context.Context, deviceName string) errors.EdgeX {
+	c.mutex.Lock()
+	hanging := c.hanging[deviceName]
+	c.mutex.Unlock()
+
+	if hanging {
+		<-ctx.Done()
+		return errors.NewCommonEdgeX(errors.KindCommunicationError, fmt.Sprintf("device '%s' did not answer", deviceName), ctx.Err())
+	}
+	return nil
+}
+
+func (c *fakeCoreCommand) AllDeviceCoreCommands(ctx context.Context, offset int, limit int) (responses.MultiDeviceCoreCommandsResponse, errors.EdgeX) {
+	return responses.MultiDeviceCoreCommandsResponse{}, errors.NewCommonEdgeX(errors.KindNotImplemented, "not implemented", nil)
+}
+
+func (c *fakeCoreCommand) DeviceCoreCommandsByDeviceName(ctx context.Context, deviceName string) (responses.DeviceCoreCommandResponse, errors.EdgeX) {
+	return responses.DeviceCoreCommandResponse{}, errors.NewCommonEdgeX(errors.KindNotImplemented, "not implemented", nil)
+}
+
+func (c *fakeCoreCommand) IssueGetCommandByName(ctx context.Context, deviceName string, commandName string, dsPushEvent string, dsReturnEvent string) (*responses.EventResponse, errors.EdgeX) {
+	if err := c.wait(ctx, deviceName); err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.gets++
+	resources, ok := c.values[deviceName]
+	if !ok {
+		return nil, errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, fmt.Sprintf("device '%s' does not exist", deviceName), nil)
+	}
+	event := dtos.Event{DeviceName: deviceName, SourceName: commandName}
+	for name, value := range resources {
+		event.Readings = append(event.Readings, dtos.BaseReading{
+			DeviceName:    deviceName,
+			ResourceName:  name,
+			SimpleReading: dtos.SimpleReading{Value: value},
+		})
+	}
+	return &responses.EventResponse{Event: event}, nil
+}
+
+func (c *fakeCoreCommand) IssueSetCommandByName(ctx context.Context, deviceName string, commandName string, settings map[string]string) (dtoCommon.BaseResponse, errors.EdgeX) {
+	if err := c.wait(ctx, deviceName); err != nil {
+		return dtoCommon.BaseResponse{}, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.sets = append(c.sets, setCommand{deviceName: deviceName, commandName: commandName, settings: settings})
+	if c.values[deviceName] == nil {
+		c.values[deviceName] = make(map[string]string)
+	}
+	for name, value := range settings {
+		c.values[deviceName][name] = value
+	}
+	return dtoCommon.BaseResponse{}, nil
+}
@@ -0,0 +1,97 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
+
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// actionExecutor performs the effects of the actions on the other services, the same execution
+// logic runs with the live executor or with the dry-run executor which only records the effects
+type actionExecutor interface {
+	readResource(ctx context.Context, remote string, deviceName string, resourceName string) (string, error)
+	setCommand(ctx context.Context, remote string, deviceName string, commandName string, params map[string]string) error
+	callWebhook(ctx context.Context, webhook *models.WebhookAction, body []byte, timeout time.Duration) error
+	publish(ctx context.Context, topic string, payload []byte, qos int, retained bool) error
+	startRamp(ruleId string, ramp *models.RampAction, resourceName string, start float64, duration time.Duration, stepTimeout time.Duration)
+	saveSnapshot(snapshot models.Snapshot) error
+	loadSnapshot(name string) (models.Snapshot, bool)
+	deleteSnapshot(name string) error
+	scheduleAction(rule models.Rule, index int, action models.Action, tc triggerContext) error
+	sendNotification(notification dtos.Notification) error
+	// simulated returns true if the effects are only recorded, the readings then never change by themselves
+	simulated() bool
+}
+
+// liveExecutor sends the commands, requests and notifications to the other services
+type liveExecutor struct{}
+
+func (liveExecutor) readResource(ctx context.Context, remote string, deviceName string, resourceName string) (string, error) {
+	cc, err := commandClientFor(remote)
+	if err != nil {
+		return "", err
+	}
+	return readResourceValueWith(ctx, cc, deviceName, resourceName)
+}
+
+func (liveExecutor) setCommand(ctx context.Context, remote string, deviceName string, commandName string, params map[string]string) error {
+	cc, err := commandClientFor(remote)
+	if err != nil {
+		return err
+	}
+	if _, edgexErr := cc.IssueSetCommandByName(ctx, deviceName, commandName, params); edgexErr != nil {
+		return edgexErr
+	}
+	return nil
+}
+
+func (liveExecutor) callWebhook(ctx context.Context, webhook *models.WebhookAction, body []byte, timeout time.Duration) error {
+	_, err := webhookClient.Call(ctx, webhook.Method, webhook.Url, webhook.Headers, body, timeout, webhook.ExpectedStatusCodes)
+	return err
+}
+
+func (liveExecutor) publish(ctx context.Context, topic string, payload []byte, qos int, retained bool) error {
+	if messageBusClient == nil {
+		return fmt.Errorf("message bus is not configured")
+	}
+	return messageBusClient.Publish(ctx, topic, payload, qos, retained)
+}
+
+func (liveExecutor) startRamp(ruleId string, ramp *models.RampAction, resourceName string, start float64, duration time.Duration, stepTimeout time.Duration) {
+	startRamp(ruleId, ramp, resourceName, start, duration, stepTimeout)
+}
+
+func (liveExecutor) saveSnapshot(snapshot models.Snapshot) error {
+	return saveSnapshot(snapshot)
+}
+
+func (liveExecutor) loadSnapshot(name string) (models.Snapshot, bool) {
+	return loadSnapshot(name)
+}
+
+func (liveExecutor) deleteSnapshot(name string) error {
+	if edgexErr := DeleteSnapshotByName(name); edgexErr != nil {
+		return edgexErr
+	}
+	return nil
+}
+
+func (liveExecutor) scheduleAction(rule models.Rule, index int, action models.Action, tc triggerContext) error {
+	return scheduleAction(rule, index, action, tc)
+}
+
+func (liveExecutor) sendNotification(notification dtos.Notification) error {
+	request := requests.NewAddNotificationRequest(notification)
+	return requestWithTimeout(func(ctx context.Context) error {
+		return notificationClient.SendNotifications(ctx, []requests.AddNotificationRequest{request})
+	})
+}
+
+func (liveExecutor) simulated() bool {
+	return false
+}
@@ -0,0 +1,112 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/responses"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+
+	"github.com/rddigital/device-scenario/internal/cache"
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// scheduleBackend is the status of the interval and the interval action of a schedule condition
+type scheduleBackend struct {
+	Interval       interface{} `json:"interval,omitempty"`
+	IntervalAction interface{} `json:"intervalAction,omitempty"`
+}
+
+// ExplainRuleByName returns the live state of the conditions of the rule, with the status of
+// the Kuiper rules and the interval actions which update them
+func ExplainRuleByName(name string) (models.RuleExplanation, errors.EdgeX) {
+	rule, ok := cache.Rules().ForName(name)
+	if !ok {
+		return models.RuleExplanation{}, errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, fmt.Sprintf("rule '%s' does not exists", name), nil)
+	}
+
+	states := cache.Rules().GetConditionStatesRule(rule.Id)
+	changes := cache.Rules().GetChangesRule(rule.Id)
+	explanation := models.RuleExplanation{
+		RuleId:     rule.Id,
+		RuleName:   rule.Name,
+		AdminState: string(rule.AdminState),
+		Result:     combineConditionStates(rule, states),
+		LastFired:  cache.Rules().GetFiredRule(rule.Id),
+		Conditions: make([]models.ConditionExplanation, len(rule.Conditions)),
+	}
+
+	now := time.Now()
+	for index, condition := range rule.Conditions {
+		c := models.ConditionExplanation{
+			Index:       index,
+			Condition:   condition,
+			BackendName: generateName(rule.Id, index),
+		}
+		if index < len(states) {
+			c.State = states[index]
+		}
+		if index < len(changes) {
+			c.ConditionChange = changes[index]
+			c.Expired = condition.Expired(changes[index].LastUpdated, now)
+		}
+
+		var err error
+		if condition.Type == cm.ThresholdRuleType {
+			c.Backend, err = kuiperRuleStatus(c.BackendName)
+		} else {
+			c.Backend, err = intervalActionStatus(c.BackendName)
+		}
+		if err != nil {
+			c.BackendError = err.Error()
+		}
+		explanation.Conditions[index] = c
+	}
+
+	return explanation, nil
+}
+
+// kuiperRuleStatus returns the status of the Kuiper rule, decoded if it is JSON
+func kuiperRuleStatus(name string) (interface{}, error) {
+	status, err := ruleEngineClient.StatusRule(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	if json.Unmarshal([]byte(status), &decoded) == nil {
+		return decoded, nil
+	}
+	return status, nil
+}
+
+// intervalActionStatus returns the interval and the interval action registered in support-scheduler
+func intervalActionStatus(name string) (interface{}, error) {
+	var backend scheduleBackend
+
+	var intervalAction responses.IntervalActionResponse
+	err := requestWithTimeout(func(ctx context.Context) error {
+		var edgexErr errors.EdgeX
+		intervalAction, edgexErr = schedulerClient.IntervalActionByName(ctx, name)
+		return edgexErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query interval action '%s' error: %s", name, err.Error())
+	}
+	backend.IntervalAction = intervalAction.Action
+
+	var interval responses.IntervalResponse
+	err = requestWithTimeout(func(ctx context.Context) error {
+		var edgexErr errors.EdgeX
+		interval, edgexErr = schedulerClient.IntervalByName(ctx, name)
+		return edgexErr
+	})
+	if err != nil {
+		return backend, fmt.Errorf("query interval '%s' error: %s", name, err.Error())
+	}
+	backend.Interval = interval.Interval
+	return backend, nil
+}
@@ -0,0 +1,175 @@
+package application
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rddigital/device-scenario/internal/cache"
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+	"github.com/rddigital/device-scenario/internal/store"
+)
+
+type historyManager struct {
+	records    []models.ExecutionRecord // sorted by start time
+	maxRecords int
+	maxAge     time.Duration
+	persist    *store.DebouncedSave
+	mutex      sync.Mutex
+}
+
+var (
+	history = &historyManager{
+		maxRecords: cm.DefaultHistoryMaxRecords,
+		maxAge:     cm.DefaultHistoryMaxAge,
+	}
+)
+
+// initHistory restores the execution records saved before the restart
+func initHistory(maxRecords int, maxAge time.Duration) {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+
+	history.maxRecords = maxRecords
+	history.maxAge = maxAge
+	history.persist = store.NewDebouncedSave(cm.HistoryStoreName, cm.DefaultPersistDelay, history.snapshot, func(err error) {
+		lc.Errorf("save execution records error: %s", err.Error())
+	})
+	if _, err := store.Local().Load(cm.HistoryStoreName, &history.records); err != nil {
+		lc.Errorf("load execution records error: %s", err.Error())
+	}
+	sort.SliceStable(history.records, func(i, j int) bool {
+		return history.records[i].Started < history.records[j].Started
+	})
+	history.prune()
+	history.save()
+}
+
+// newExecutionRecord starts the record of an execution of the rule
+func newExecutionRecord(rule models.Rule, source string, contentTrigger models.ContentTrigger) models.ExecutionRecord {
+	record := models.ExecutionRecord{
+		RuleId:          rule.Id,
+		RuleName:        rule.Name,
+		Source:          source,
+		TriggerValue:    contentTrigger.TriggerValue,
+		ConditionStates: cache.Rules().GetConditionStatesRule(rule.Id),
+		Started:         time.Now().UnixNano(),
+	}
+	if contentTrigger.TriggerIndex != nil {
+		record.TriggerIndex = *contentTrigger.TriggerIndex
+	}
+	if contentTrigger.TriggerState != nil {
+		record.TriggerState = *contentTrigger.TriggerState
+	}
+	return record
+}
+
+// recordExecution completes the record with the result and saves it
+func recordExecution(record models.ExecutionRecord, result models.ExecutionResult) {
+	id, _ := uuid.NewUUID()
+	record.Id = id.String()
+	record.Duration = time.Now().UnixNano() - record.Started
+	record.Result = result
+	record.Outcome = models.ExecutionSucceeded
+	if result.Failed > 0 {
+		record.Outcome = models.ExecutionFailed
+	}
+
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+
+	// executions may end in another order than they started
+	i := sort.Search(len(history.records), func(i int) bool {
+		return history.records[i].Started > record.Started
+	})
+	history.records = append(history.records, models.ExecutionRecord{})
+	copy(history.records[i+1:], history.records[i:])
+	history.records[i] = record
+	history.prune()
+	history.save()
+}
+
+// RecordScenarioExecution records the execution of the actions of a ManualScenario device
+func RecordScenarioExecution(name string, started time.Time, result models.ExecutionResult) {
+	record := models.ExecutionRecord{
+		RuleName: name,
+		Source:   models.ManualSource,
+		Started:  started.UnixNano(),
+	}
+	recordExecution(record, result)
+}
+
+// QueryExecutionRecords returns the records matching the filter from the newest, and the number of matching records
+func QueryExecutionRecords(filter models.ExecutionRecordFilter) ([]models.ExecutionRecord, int) {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+
+	matched := make([]models.ExecutionRecord, 0)
+	for i := len(history.records) - 1; i >= 0; i-- {
+		r := history.records[i]
+		if filter.RuleName != "" && r.RuleName != filter.RuleName {
+			continue
+		}
+		if filter.Start > 0 && r.Started < filter.Start {
+			continue
+		}
+		if filter.End > 0 && r.Started > filter.End {
+			continue
+		}
+		if filter.Outcome != "" && r.Outcome != filter.Outcome {
+			continue
+		}
+		matched = append(matched, r)
+	}
+
+	total := len(matched)
+	if filter.Offset >= total {
+		return []models.ExecutionRecord{}, total
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit >= 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total
+}
+
+// prune removes the records exceeding the retention, it must be called with the lock held
+func (hm *historyManager) prune() {
+	if hm.maxAge > 0 {
+		oldest := time.Now().Add(-hm.maxAge).UnixNano()
+		i := sort.Search(len(hm.records), func(i int) bool {
+			return hm.records[i].Started >= oldest
+		})
+		hm.records = hm.records[i:]
+	}
+	if hm.maxRecords > 0 && len(hm.records) > hm.maxRecords {
+		hm.records = hm.records[len(hm.records)-hm.maxRecords:]
+	}
+}
+
+// save schedules the save of the records, it must be called with the lock held
+func (hm *historyManager) save() {
+	if hm.persist != nil {
+		hm.persist.Schedule()
+	}
+}
+
+// snapshot returns a copy of the records to save
+func (hm *historyManager) snapshot() interface{} {
+	hm.mutex.Lock()
+	defer hm.mutex.Unlock()
+
+	records := make([]models.ExecutionRecord, len(hm.records))
+	copy(records, hm.records)
+	return records
+}
+
+// flushHistory saves the records at once if a save is scheduled
+func flushHistory() {
+	if history.persist != nil {
+		history.persist.Flush()
+	}
+}
@@ -0,0 +1,122 @@
+package application
+
+import (
+	"testing"
+	"time"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+	"github.com/rddigital/device-scenario/internal/store"
+)
+
+// resetHistory starts the history without records and with the retention
+func resetHistory(t *testing.T, maxRecords int, maxAge time.Duration) {
+	t.Helper()
+	flushHistory()
+	if err := store.Local().Delete(cm.HistoryStoreName); err != nil {
+		t.Fatalf("delete execution records error: %v", err)
+	}
+	history.records = nil
+	initHistory(maxRecords, maxAge)
+	t.Cleanup(func() {
+		flushHistory()
+		history.records = nil
+		initHistory(cm.DefaultHistoryMaxRecords, cm.DefaultHistoryMaxAge)
+	})
+}
+
+func recordNames(records []models.ExecutionRecord) []string {
+	names := make([]string, 0, len(records))
+	for _, r := range records {
+		names = append(names, r.RuleName)
+	}
+	return names
+}
+
+func TestRecordExecutionPrunesByCount(t *testing.T) {
+	resetHistory(t, 3, 0)
+	now := time.Now()
+	for i, name := range []string{"a", "b", "c", "d", "e"} {
+		RecordScenarioExecution(name, now.Add(time.Duration(i)*time.Second), models.ExecutionResult{})
+	}
+
+	records, total := QueryExecutionRecords(models.ExecutionRecordFilter{Limit: -1})
+	if got := recordNames(records); total != 3 || len(got) != 3 || got[0] != "e" || got[1] != "d" || got[2] != "c" {
+		t.Errorf("records = %v (total %d), want the 3 newest [e d c]", got, total)
+	}
+}
+
+func TestRecordExecutionPrunesByAge(t *testing.T) {
+	resetHistory(t, 0, time.Hour)
+	now := time.Now()
+	RecordScenarioExecution("yesterday", now.Add(-24*time.Hour), models.ExecutionResult{})
+	RecordScenarioExecution("today", now.Add(-time.Minute), models.ExecutionResult{})
+
+	records, _ := QueryExecutionRecords(models.ExecutionRecordFilter{Limit: -1})
+	if got := recordNames(records); len(got) != 1 || got[0] != "today" {
+		t.Errorf("records = %v, want [today]", got)
+	}
+}
+
+func TestQueryExecutionRecords(t *testing.T) {
+	resetHistory(t, 100, 0)
+	base := time.Now().Add(-time.Hour)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	failed := models.ExecutionResult{Failed: 1}
+
+	// recorded out of their start order, as executions may end in another order
+	RecordScenarioExecution("lights-on", at(10), models.ExecutionResult{})
+	RecordScenarioExecution("lights-off", at(30), failed)
+	RecordScenarioExecution("lights-on", at(20), failed)
+	RecordScenarioExecution("lights-on", at(40), models.ExecutionResult{})
+
+	tests := []struct {
+		name      string
+		filter    models.ExecutionRecordFilter
+		wantTimes []time.Time
+		wantTotal int
+	}{
+		{"all from the newest", models.ExecutionRecordFilter{Limit: -1}, []time.Time{at(40), at(30), at(20), at(10)}, 4},
+		{"rule name", models.ExecutionRecordFilter{RuleName: "lights-on", Limit: -1}, []time.Time{at(40), at(20), at(10)}, 3},
+		{"outcome", models.ExecutionRecordFilter{Outcome: models.ExecutionFailed, Limit: -1}, []time.Time{at(30), at(20)}, 2},
+		{"time range", models.ExecutionRecordFilter{Start: at(15).UnixNano(), End: at(35).UnixNano(), Limit: -1}, []time.Time{at(30), at(20)}, 2},
+		{"offset and limit", models.ExecutionRecordFilter{Offset: 1, Limit: 2}, []time.Time{at(30), at(20)}, 4},
+		{"offset past the end", models.ExecutionRecordFilter{Offset: 4, Limit: -1}, []time.Time{}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, total := QueryExecutionRecords(tt.filter)
+			if total != tt.wantTotal || len(records) != len(tt.wantTimes) {
+				t.Fatalf("%d records (total %d), want %d (total %d)", len(records), total, len(tt.wantTimes), tt.wantTotal)
+			}
+			for i, r := range records {
+				if r.Started != tt.wantTimes[i].UnixNano() {
+					t.Errorf("record %d started at %s, want %s", i, time.Unix(0, r.Started), tt.wantTimes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestInitHistoryRestoresSavedRecords(t *testing.T) {
+	resetHistory(t, 100, 0)
+	RecordScenarioExecution("lights-on", time.Now().Add(-time.Minute), models.ExecutionResult{})
+
+	// a restart with a lower retention, the records are saved when the service stops
+	flushHistory()
+	history.records = nil
+	initHistory(100, time.Second)
+	if records, total := QueryExecutionRecords(models.ExecutionRecordFilter{Limit: -1}); total != 0 {
+		t.Errorf("records = %v, want the record older than the retention pruned", recordNames(records))
+	}
+
+	RecordScenarioExecution("lights-off", time.Now(), models.ExecutionResult{})
+	flushHistory()
+	history.records = nil
+	initHistory(100, time.Hour)
+	records, _ := QueryExecutionRecords(models.ExecutionRecordFilter{Limit: -1})
+	if got := recordNames(records); len(got) != 1 || got[0] != "lights-off" {
+		t.Errorf("restored records = %v, want [lights-off]", got)
+	}
+}
@@ -0,0 +1,170 @@
+package application
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rddigital/device-scenario/internal/cache"
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// ruleEdge is a resource written by the actions of a rule and watched by a threshold condition of another rule,
+// the resource is empty when the body of the command does not tell which resources it writes
+type ruleEdge struct {
+	to           string
+	deviceName   string
+	resourceName string
+}
+
+// resourceWrite is a resource written by an action
+type resourceWrite struct {
+	deviceName   string
+	resourceName string // empty when not known
+}
+
+// ruleWrittenResources returns the local resources written by the command and ramp actions, including the
+// actions of the branches. The devices of the targets and the actions of the scenarios are ignored
+func ruleWrittenResources(actions []models.Action) []resourceWrite {
+	writes := make([]resourceWrite, 0)
+	for _, action := range actions {
+		switch {
+		case action.If != nil:
+			writes = append(writes, ruleWrittenResources(action.If.Then)...)
+			writes = append(writes, ruleWrittenResources(action.If.Else)...)
+		case action.Wait != nil:
+			writes = append(writes, ruleWrittenResources(action.Wait.OnTimeout)...)
+		case action.Ramp != nil:
+			if action.Ramp.Remote != "" {
+				continue
+			}
+			resourceName := action.Ramp.ResourceName
+			if resourceName == "" {
+				resourceName = action.Ramp.CommandName
+			}
+			writes = append(writes, resourceWrite{deviceName: action.Ramp.DeviceName, resourceName: resourceName})
+		case action.ActionType() == models.CommandActionType && action.Target == nil && action.Remote == "":
+			params, err := parseBody(action.Body)
+			if err != nil || len(params) == 0 {
+				writes = append(writes, resourceWrite{deviceName: action.DeviceName})
+				continue
+			}
+			for resourceName := range params {
+				writes = append(writes, resourceWrite{deviceName: action.DeviceName, resourceName: resourceName})
+			}
+		}
+	}
+	return writes
+}
+
+// ruleGraph returns the edges from each rule to the rules with a threshold condition on a resource it writes,
+// or on the device of a command whose resources are not known
+func ruleGraph(rules []models.Rule) map[string][]ruleEdge {
+	type watcher struct {
+		ruleId       string
+		resourceName string
+	}
+	watchers := make(map[string][]watcher) // key is device name
+	for _, rule := range rules {
+		for _, condition := range rule.Conditions {
+			if condition.Type == cm.ThresholdRuleType {
+				watchers[condition.DeviceThreshold] = append(watchers[condition.DeviceThreshold], watcher{ruleId: rule.Id, resourceName: condition.ResourceThreshold})
+			}
+		}
+	}
+
+	graph := make(map[string][]ruleEdge)
+	for _, rule := range rules {
+		edges := make(map[string]int) // key is the rule id of the edge, value is its index
+		for _, write := range ruleWrittenResources(rule.Actions) {
+			for _, w := range watchers[write.deviceName] {
+				if write.resourceName != "" && write.resourceName != w.resourceName {
+					continue
+				}
+				edge := ruleEdge{to: w.ruleId, deviceName: write.deviceName, resourceName: write.resourceName}
+				if i, ok := edges[w.ruleId]; !ok {
+					edges[w.ruleId] = len(graph[rule.Id])
+					graph[rule.Id] = append(graph[rule.Id], edge)
+				} else if graph[rule.Id][i].resourceName == "" {
+					// a written resource is known to be watched
+					graph[rule.Id][i] = edge
+				}
+			}
+		}
+	}
+	return graph
+}
+
+// detectLoops returns a warning with the shortest automation loop through the rule, the actions of the
+// rules of the loop may then trigger each other again and again. A loop through a command whose resources
+// are not known is only a possible loop
+func detectLoops(rule models.Rule) []string {
+	warnings := make([]string, 0)
+	rules := cache.Rules().All()
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Name < rules[j].Name
+	})
+	names := make(map[string]string)
+	for _, r := range rules {
+		names[r.Id] = r.Name
+	}
+	graph := ruleGraph(rules)
+
+	if path := shortestLoop(graph, rule.Id, false); path != nil {
+		warnings = append(warnings, fmt.Sprintf("automation loop: %s", formatLoop(path, names)))
+	} else if path = shortestLoop(graph, rule.Id, true); path != nil {
+		warnings = append(warnings, fmt.Sprintf("possible automation loop: %s", formatLoop(path, names)))
+	}
+	return warnings
+}
+
+// shortestLoop returns the edges of the shortest loop from the rule back to itself, found by a breadth-first
+// search. The edges of unknown resources are only followed if possible is set
+func shortestLoop(graph map[string][]ruleEdge, ruleId string, possible bool) []ruleEdge {
+	parents := make(map[string]ruleEdge)
+	parentIds := make(map[string]string)
+	queue := []string{ruleId}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, edge := range graph[id] {
+			if edge.resourceName == "" && !possible {
+				continue
+			}
+			if edge.to == ruleId {
+				path := []ruleEdge{edge}
+				for from := id; from != ruleId; from = parentIds[from] {
+					path = append(path, parents[from])
+				}
+				for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			if _, ok := parents[edge.to]; !ok {
+				parents[edge.to] = edge
+				parentIds[edge.to] = id
+				queue = append(queue, edge.to)
+			}
+		}
+	}
+	return nil
+}
+
+// formatLoop returns the rules of the loop with the resource written by each of them,
+// e.g. 'heat-on' (resource 'power' of device 'Heater') -> 'cool-on' (device 'Fan') -> 'heat-on'
+func formatLoop(path []ruleEdge, names map[string]string) string {
+	steps := make([]string, 0, len(path)+1)
+	from := path[len(path)-1].to
+	for _, edge := range path {
+		if edge.resourceName == "" {
+			steps = append(steps, fmt.Sprintf("'%s' (device '%s')", names[from], edge.deviceName))
+		} else {
+			steps = append(steps, fmt.Sprintf("'%s' (resource '%s' of device '%s')", names[from], edge.resourceName, edge.deviceName))
+		}
+		from = edge.to
+	}
+	steps = append(steps, fmt.Sprintf("'%s'", names[from]))
+	return strings.Join(steps, " -> ")
+}
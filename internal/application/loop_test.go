@@ -0,0 +1,143 @@
+package application
+
+import (
+	"reflect"
+	"testing"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+func TestDetectLoops(t *testing.T) {
+	// watcher returns a rule with a threshold condition on the level of the device, running the actions
+	watcher := func(name string, deviceName string, actions ...models.Action) models.Rule {
+		return models.Rule{
+			Id:   name + "-id",
+			Name: name,
+			Conditions: []models.Condition{{
+				Logic: cm.AndLogic, Type: cm.ThresholdRuleType,
+				DeviceThreshold: deviceName, ResourceThreshold: "level", OperatorThreshold: ">", ValueThreshold: "30",
+			}},
+			Actions: actions,
+		}
+	}
+	set := func(deviceName string) models.Action {
+		return models.Action{DeviceName: deviceName, CommandName: "level", Body: `{"level":"50"}`}
+	}
+	power := func(deviceName string) models.Action {
+		return models.Action{DeviceName: deviceName, CommandName: "power", Body: `{"power":"on"}`}
+	}
+	unknown := func(deviceName string) models.Action {
+		return models.Action{DeviceName: deviceName, CommandName: "reset", Body: `{}`}
+	}
+	ramp := models.Action{Ramp: &models.RampAction{DeviceName: "heater", CommandName: "level", End: 100, Duration: "1m", Steps: 10}}
+	remote := set("heater")
+	remote.Remote = "site-b"
+
+	tests := []struct {
+		name  string
+		rules []models.Rule
+		want  []string
+	}{
+		{
+			name:  "no loop",
+			rules: []models.Rule{watcher("cooling", "thermostat", set("fan")), watcher("ventilation", "fan", set("window"))},
+			want:  []string{},
+		},
+		{
+			name:  "self loop",
+			rules: []models.Rule{watcher("cooling", "fan", set("fan"))},
+			want:  []string{"automation loop: 'cooling' (resource 'level' of device 'fan') -> 'cooling'"},
+		},
+		{
+			name:  "self rule writing another resource",
+			rules: []models.Rule{watcher("cooling", "fan", power("fan"))},
+			want:  []string{},
+		},
+		{
+			name:  "two rules",
+			rules: []models.Rule{watcher("cooling", "thermostat", set("fan")), watcher("ventilation", "fan", set("thermostat"))},
+			want:  []string{"automation loop: 'cooling' (resource 'level' of device 'fan') -> 'ventilation' (resource 'level' of device 'thermostat') -> 'cooling'"},
+		},
+		{
+			name:  "other resources of the watched devices",
+			rules: []models.Rule{watcher("cooling", "thermostat", power("fan")), watcher("ventilation", "fan", power("thermostat"))},
+			want:  []string{},
+		},
+		{
+			name:  "unknown resource",
+			rules: []models.Rule{watcher("cooling", "thermostat", unknown("fan")), watcher("ventilation", "fan", set("thermostat"))},
+			want:  []string{"possible automation loop: 'cooling' (device 'fan') -> 'ventilation' (resource 'level' of device 'thermostat') -> 'cooling'"},
+		},
+		{
+			name: "known loop before a shorter possible one",
+			rules: []models.Rule{
+				watcher("cooling", "thermostat", unknown("thermostat"), set("fan")),
+				watcher("ventilation", "fan", set("thermostat")),
+			},
+			want: []string{"automation loop: 'cooling' (resource 'level' of device 'fan') -> 'ventilation' (resource 'level' of device 'thermostat') -> 'cooling'"},
+		},
+		{
+			name: "through a ramp",
+			rules: []models.Rule{
+				watcher("cooling", "thermostat", set("fan")),
+				watcher("heating", "fan", ramp),
+				watcher("ventilation", "heater", set("thermostat")),
+			},
+			want: []string{"automation loop: 'cooling' (resource 'level' of device 'fan') -> 'heating' (resource 'level' of device 'heater') -> 'ventilation' (resource 'level' of device 'thermostat') -> 'cooling'"},
+		},
+		{
+			name: "shortest loop",
+			rules: []models.Rule{
+				watcher("cooling", "thermostat", set("fan"), set("heater")),
+				watcher("heating", "fan", ramp),
+				watcher("ventilation", "heater", set("thermostat")),
+			},
+			want: []string{"automation loop: 'cooling' (resource 'level' of device 'heater') -> 'ventilation' (resource 'level' of device 'thermostat') -> 'cooling'"},
+		},
+		{
+			name: "branch actions",
+			rules: []models.Rule{
+				watcher("cooling", "thermostat", models.Action{If: &models.IfAction{Else: []models.Action{set("fan")}}}),
+				watcher("ventilation", "fan", models.Action{Wait: &models.WaitAction{OnTimeout: []models.Action{set("thermostat")}}}),
+			},
+			want: []string{"automation loop: 'cooling' (resource 'level' of device 'fan') -> 'ventilation' (resource 'level' of device 'thermostat') -> 'cooling'"},
+		},
+		{
+			name:  "remote device is not watched",
+			rules: []models.Rule{watcher("cooling", "thermostat", remote), watcher("heating", "heater", set("thermostat"))},
+			want:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loadRules(t, tt.rules...)
+			if got := detectLoops(tt.rules[0]); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectLoops() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStormBreakerRecord(t *testing.T) {
+	initStormBreaker(3)
+	defer initStormBreaker(cm.DefaultStormMaxFiresPerMinute)
+
+	for i := 1; i <= 3; i++ {
+		if fires, storm := storms.record("doorbell"); fires != i || storm {
+			t.Fatalf("fire %d: record() = %d, %v, want %d, false", i, fires, storm, i)
+		}
+	}
+	if fires, storm := storms.record("doorbell"); fires != 4 || !storm {
+		t.Errorf("record() = %d, %v, want 4, true", fires, storm)
+	}
+	if fires, storm := storms.record("porch"); fires != 1 || storm {
+		t.Errorf("other rule record() = %d, %v, want 1, false", fires, storm)
+	}
+
+	storms.reset("doorbell")
+	if fires, _ := storms.record("doorbell"); fires != 1 {
+		t.Errorf("record() after reset = %d, want 1", fires)
+	}
+}
@@ -0,0 +1,58 @@
+package application
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+
+	"github.com/rddigital/device-scenario/internal/cache"
+	"github.com/rddigital/device-scenario/internal/client"
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+	"github.com/rddigital/device-scenario/internal/store"
+)
+
+func TestMain(m *testing.M) {
+	lc = logger.NewMockClient()
+	webhookClient = client.NewHttpWebhookClient(cm.DefaultWebhookTimeout)
+	actionTimeout = cm.DefaultActionTimeout
+	ruleTimeout = cm.DefaultRuleTimeout
+
+	dir, err := ioutil.TempDir("", "device-scenario")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err = store.InitStore(dir); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	initTimers()
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// loadRules replaces the rules of the cache and their command writes, without the condition
+// states of the previous tests
+func loadRules(t *testing.T, rules ...models.Rule) {
+	t.Helper()
+	// the ramps of the previous tests read the rules of the cache
+	stopRamps()
+	cache.Flush()
+	if err := store.Local().Delete(cm.StatesStoreName); err != nil {
+		t.Fatalf("delete condition states error: %v", err)
+	}
+	cache.InitCacheFromRules(lc, rules)
+	for _, rule := range rules {
+		ruleWrites.set(rule)
+	}
+	t.Cleanup(func() {
+		for _, rule := range rules {
+			ruleWrites.delete(rule.Id)
+		}
+	})
+}
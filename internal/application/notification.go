@@ -0,0 +1,48 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	ctModels "github.com/edgexfoundry/go-mod-core-contracts/v2/models"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// sendNotification sends the notification of the rule, the default values are used
+// for the fields which are not configured. It is sent even if the execution expired
+func sendNotification(rule models.Rule, tc triggerContext) error {
+	var setting models.Notification
+	if rule.Notification != nil {
+		setting = *rule.Notification
+	}
+
+	contentTemplate := setting.Content
+	if contentTemplate == "" {
+		contentTemplate = cm.DefaultNotificationContent
+	}
+	content, err := renderTemplate(contentTemplate, tc)
+	if err != nil {
+		return fmt.Errorf("render content error: %s", err.Error())
+	}
+
+	category := setting.Category
+	if category == "" && len(setting.Labels) == 0 {
+		category = cm.DefaultNotificationCategory
+	}
+	severity := setting.Severity
+	if severity == "" {
+		severity = ctModels.Normal
+	}
+
+	return tc.executor.sendNotification(dtos.Notification{
+		Category:    category,
+		Labels:      setting.Labels,
+		Content:     content,
+		Description: setting.Description,
+		Sender:      cm.NotificationSender,
+		Severity:    severity,
+		Status:      ctModels.New,
+	})
+}
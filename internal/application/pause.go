@@ -0,0 +1,177 @@
+package application
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+
+	"github.com/rddigital/device-scenario/internal/cache"
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+	"github.com/rddigital/device-scenario/internal/store"
+)
+
+// pauseManager holds the maintenance mode, which stops the auto rules and the ManualScenario triggers
+type pauseManager struct {
+	state models.PauseState
+	timer *time.Timer
+	mutex sync.Mutex
+}
+
+var (
+	pause = &pauseManager{}
+)
+
+// initPause restores the maintenance mode saved before the restart,
+// the service resumes at once if the auto-resume time passed during the downtime
+func initPause() {
+	var state models.PauseState
+	if _, err := store.Local().Load(cm.PauseStoreName, &state); err != nil {
+		lc.Errorf("load pause state error: %s", err.Error())
+		return
+	}
+
+	pause.mutex.Lock()
+	defer pause.mutex.Unlock()
+
+	if state.Paused && state.ResumeAt != 0 && state.ResumeAt <= time.Now().UnixNano() {
+		lc.Infof("automations resumed: auto-resume time passed during the downtime")
+		pause.state = models.PauseState{}
+		pause.save()
+		return
+	}
+	pause.state = state
+	if state.Paused {
+		lc.Warnf("automations paused since %s", time.Unix(0, state.Since).Format(time.RFC3339))
+		pause.startTimer()
+	}
+}
+
+// PauseAutomations pauses the auto rules and the ManualScenario triggers, a new pause replaces the current one
+func PauseAutomations(request models.PauseRequest) (models.PauseState, errors.EdgeX) {
+	now := time.Now()
+	resumeAt := request.ResumeAt
+	if request.Duration != "" {
+		if resumeAt != 0 {
+			return models.PauseState{}, errors.NewCommonEdgeX(errors.KindContractInvalid, "only one of duration and resumeAt can be set", nil)
+		}
+		duration, err := time.ParseDuration(request.Duration)
+		if err != nil || duration <= 0 {
+			return models.PauseState{}, errors.NewCommonEdgeX(errors.KindContractInvalid, fmt.Sprintf("invalid duration '%s'", request.Duration), err)
+		}
+		resumeAt = now.Add(duration).UnixNano()
+	}
+	if resumeAt != 0 && resumeAt <= now.UnixNano() {
+		return models.PauseState{}, errors.NewCommonEdgeX(errors.KindContractInvalid, "resumeAt must be in the future", nil)
+	}
+	for _, name := range request.Exempt {
+		if _, err := scenarioActions(name); err != nil {
+			return models.PauseState{}, errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, fmt.Sprintf("exempt %s", err.Error()), nil)
+		}
+	}
+
+	pause.mutex.Lock()
+	pause.state = models.PauseState{
+		Paused:   true,
+		Since:    now.UnixNano(),
+		ResumeAt: resumeAt,
+		Exempt:   request.Exempt,
+		Reason:   request.Reason,
+	}
+	pause.startTimer()
+	pause.save()
+	state := pause.state
+	pause.mutex.Unlock()
+
+	lc.Warnf("automations paused: %s", request.Reason)
+	haltPausedRules()
+	return state, nil
+}
+
+// haltPausedRules cancels the delayed actions and stops the ramps of the paused rules,
+// they would otherwise keep writing to the devices during the maintenance
+func haltPausedRules() {
+	for _, rule := range cache.Rules().All() {
+		if IsPaused(rule.Name) {
+			cancelRuleTimers(rule.Id, false)
+			cancelRuleRamps(rule.Id)
+		}
+	}
+}
+
+// ResumeAutomations ends the maintenance mode
+func ResumeAutomations() models.PauseState {
+	pause.mutex.Lock()
+	defer pause.mutex.Unlock()
+
+	pause.resume()
+	return pause.state
+}
+
+func GetPauseState() models.PauseState {
+	pause.mutex.Lock()
+	defer pause.mutex.Unlock()
+
+	return pause.state
+}
+
+// IsPaused returns true if the rule or the ManualScenario with the name must not run
+func IsPaused(name string) bool {
+	pause.mutex.Lock()
+	defer pause.mutex.Unlock()
+
+	if !pause.state.Paused {
+		return false
+	}
+	for _, exempt := range pause.state.Exempt {
+		if exempt == name {
+			return false
+		}
+	}
+	return true
+}
+
+// startTimer restarts the auto-resume timer, the caller must hold the mutex
+func (pm *pauseManager) startTimer() {
+	if pm.timer != nil {
+		pm.timer.Stop()
+		pm.timer = nil
+	}
+	if pm.state.ResumeAt == 0 {
+		return
+	}
+
+	since := pm.state.Since
+	pm.timer = time.AfterFunc(time.Until(time.Unix(0, pm.state.ResumeAt)), func() {
+		pm.mutex.Lock()
+		defer pm.mutex.Unlock()
+
+		// the pause was replaced or ended meanwhile
+		if !pm.state.Paused || pm.state.Since != since {
+			return
+		}
+		pm.resume()
+	})
+}
+
+// resume ends the maintenance mode, the caller must hold the mutex
+func (pm *pauseManager) resume() {
+	if pm.timer != nil {
+		pm.timer.Stop()
+		pm.timer = nil
+	}
+	if pm.state.Paused {
+		lc.Infof("automations resumed")
+	}
+	pm.state = models.PauseState{}
+	pm.save()
+}
+
+// save persists the maintenance mode, the caller must hold the mutex
+func (pm *pauseManager) save() {
+	if err := store.Local().Save(cm.PauseStoreName, pm.state); err != nil {
+		lc.Errorf("save pause state error: %s", err.Error())
+	}
+}
@@ -0,0 +1,153 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+	ctModels "github.com/edgexfoundry/go-mod-core-contracts/v2/models"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+	"github.com/rddigital/device-scenario/internal/store"
+)
+
+func TestPauseAutomationsWithExemptionAndAutoResume(t *testing.T) {
+	loadRules(t, models.Rule{Id: "fire-alarm", Name: "fire-alarm"}, models.Rule{Id: "lights", Name: "lights"})
+	defer ResumeAutomations()
+
+	state, err := PauseAutomations(models.PauseRequest{Duration: "50ms", Exempt: []string{"fire-alarm"}, Reason: "maintenance"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !state.Paused || state.ResumeAt == 0 {
+		t.Fatalf("state = %+v, want paused with a resume time", state)
+	}
+	if !IsPaused("lights") || IsPaused("fire-alarm") {
+		t.Errorf("IsPaused(lights) = %v, IsPaused(fire-alarm) = %v, want true, false", IsPaused("lights"), IsPaused("fire-alarm"))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for IsPaused("lights") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if IsPaused("lights") {
+		t.Fatalf("automations not resumed after the duration")
+	}
+
+	var saved models.PauseState
+	if _, err := store.Local().Load(cm.PauseStoreName, &saved); err != nil {
+		t.Fatalf("load pause state error: %v", err)
+	}
+	if saved.Paused {
+		t.Errorf("saved state = %+v, want resumed", saved)
+	}
+}
+
+func TestPauseAutomationsReplacesThePause(t *testing.T) {
+	defer ResumeAutomations()
+
+	if _, err := PauseAutomations(models.PauseRequest{Duration: "30ms"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := PauseAutomations(models.PauseRequest{Reason: "until further notice"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the auto-resume of the first pause does not end the second one
+	time.Sleep(60 * time.Millisecond)
+	if state := GetPauseState(); !state.Paused || state.Reason != "until further notice" {
+		t.Errorf("state = %+v, want the second pause", state)
+	}
+}
+
+func TestPauseAutomationsInvalidRequests(t *testing.T) {
+	past := time.Now().Add(-time.Minute).UnixNano()
+	future := time.Now().Add(time.Minute).UnixNano()
+	requests := map[string]models.PauseRequest{
+		"duration and resume time": {Duration: "1h", ResumeAt: future},
+		"negative duration":        {Duration: "-1h"},
+		"invalid duration":         {Duration: "a while"},
+		"resume time in the past":  {ResumeAt: past},
+	}
+	for name, request := range requests {
+		if _, err := PauseAutomations(request); err == nil {
+			t.Errorf("%s: PauseAutomations() succeeded", name)
+		}
+	}
+	if GetPauseState().Paused {
+		t.Errorf("automations paused by an invalid request")
+	}
+}
+
+func TestPauseAutomationsHaltsPausedRules(t *testing.T) {
+	lights := models.Rule{Id: "3c9f1e2a-7b4d-4e6f-a1b2-c3d4e5f6a7b8", Name: "lights", AdminState: ctModels.Unlocked}
+	alarm := models.Rule{Id: "8e7d6c5b-4a39-4281-b7f6-e5d4c3b2a190", Name: "fire-alarm", AdminState: ctModels.Unlocked}
+	loadRules(t, lights, alarm)
+	defer ResumeAutomations()
+	defer cancelRuleTimers(lights.Id, false)
+	defer cancelRuleTimers(alarm.Id, false)
+	defer cancelRuleRamps(lights.Id)
+
+	cc := newFakeCoreCommand()
+	commandClient = cc
+	defer func() { commandClient = nil }()
+
+	off := models.Action{DeviceName: "hall-lamp", CommandName: "switch", Body: `{"on":"false"}`, Delay: "10m"}
+	for _, rule := range []models.Rule{lights, alarm} {
+		if err := scheduleAction(rule, 0, off, triggerContext{TriggerState: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	start := 0.0
+	ramp := &models.RampAction{DeviceName: "dimmer", CommandName: "level", Start: &start, End: 100, Duration: "1s", Steps: 100}
+	if err := executeRampAction(context.Background(), ramp, actionTimeout, triggerContext{RuleId: lights.Id, executor: liveExecutor{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForSets(t, cc, 1)
+
+	if _, err := PauseAutomations(models.PauseRequest{Exempt: []string{alarm.Name}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stopped := len(cc.received())
+	time.Sleep(50 * time.Millisecond)
+	// one step may be written while the ramp is stopped
+	if n := len(cc.received()); n > stopped+1 {
+		t.Errorf("%d ramp steps written after the pause", n-stopped)
+	}
+	pending := GetAllPendingActions()
+	if len(pending) != 1 || pending[0].RuleId != alarm.Id {
+		t.Errorf("pending actions = %+v, want only the action of the exempt rule", pending)
+	}
+
+	_, err := ExecuteRuleByName(lights.Name, models.ExecuteRuleRequest{})
+	if err == nil || errors.Kind(err) != errors.KindStatusConflict {
+		t.Errorf("ExecuteRuleByName() error = %v, want kind %s", err, errors.KindStatusConflict)
+	}
+	if _, err = ExecuteRuleByName(lights.Name, models.ExecuteRuleRequest{DryRun: true}); err != nil {
+		t.Errorf("ExecuteRuleByName() dry run unexpected error: %v", err)
+	}
+}
+
+func TestInitPauseRestoresTheSavedPause(t *testing.T) {
+	defer ResumeAutomations()
+
+	if err := store.Local().Save(cm.PauseStoreName, models.PauseState{Paused: true, Since: time.Now().UnixNano(), Reason: "maintenance"}); err != nil {
+		t.Fatalf("save pause state error: %v", err)
+	}
+	initPause()
+	if !IsPaused("lights") {
+		t.Errorf("saved pause not restored")
+	}
+
+	// the resume time passed while the service was stopped
+	expired := models.PauseState{Paused: true, Since: time.Now().Add(-time.Hour).UnixNano(), ResumeAt: time.Now().Add(-time.Minute).UnixNano()}
+	if err := store.Local().Save(cm.PauseStoreName, expired); err != nil {
+		t.Fatalf("save pause state error: %v", err)
+	}
+	initPause()
+	if IsPaused("lights") {
+		t.Errorf("pause restored after its resume time")
+	}
+}
@@ -0,0 +1,208 @@
+package application
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+type queuedTrigger struct {
+	ruleId string
+	run    func()
+}
+
+// triggerQueue runs the callbacks of Kuiper and support-scheduler and the delayed actions with a bounded
+// number of workers, those of a rule are run one at a time in their order of arrival, a manual execution
+// of the rule takes the same running slot
+type triggerQueue struct {
+	pending  []queuedTrigger
+	running  map[string]bool // key is rule id
+	workers  int
+	capacity int
+	overflow string
+	dropped  uint64
+	rejected uint64
+	stopped  bool
+	mutex    sync.Mutex
+	cond     *sync.Cond
+}
+
+var (
+	triggers *triggerQueue
+)
+
+// initTriggerQueue starts the workers of the queue
+func initTriggerQueue(workers int, capacity int, overflow string) {
+	q := &triggerQueue{
+		pending:  make([]queuedTrigger, 0, capacity),
+		running:  make(map[string]bool),
+		workers:  workers,
+		capacity: capacity,
+		overflow: overflow,
+	}
+	q.cond = sync.NewCond(&q.mutex)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	triggers = q
+}
+
+// stopTriggerQueue stops the workers once their running callback is done, the queued callbacks are dropped
+func stopTriggerQueue() {
+	q := triggers
+	if q == nil {
+		return
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if n := len(q.pending); n > 0 {
+		lc.Warnf("%d queued triggers dropped on stop", n)
+	}
+	q.pending = nil
+	q.stopped = true
+	q.cond.Broadcast()
+}
+
+// EnqueueTrigger queues the callback of the condition of the rule. When the queue is full the oldest
+// callback is dropped, or the callback is rejected with a LimitExceeded error, depending on the overflow policy
+func EnqueueTrigger(id string, contentTrigger models.ContentTrigger) errors.EdgeX {
+	return enqueue(id, func() {
+		TriggerRuleById(id, contentTrigger)
+	})
+}
+
+// enqueue queues the function run for the rule, with the overflow policy of the callbacks
+func enqueue(id string, run func()) errors.EdgeX {
+	q := triggers
+	if q == nil {
+		return errors.NewCommonEdgeX(errors.KindServiceUnavailable, "trigger queue is not started", nil)
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.stopped {
+		return errors.NewCommonEdgeX(errors.KindServiceUnavailable, "trigger queue is stopped", nil)
+	}
+	if len(q.pending) >= q.capacity {
+		if q.overflow == cm.RejectOverflow {
+			q.rejected++
+			return errors.NewCommonEdgeX(errors.KindLimitExceeded, fmt.Sprintf("trigger queue is full (%d)", q.capacity), nil)
+		}
+		dropped := q.pending[0]
+		q.pending = q.pending[1:]
+		q.dropped++
+		lc.Warnf("trigger queue is full (%d): oldest trigger of rule with id '%s' dropped", q.capacity, dropped.ruleId)
+	}
+
+	q.pending = append(q.pending, queuedTrigger{ruleId: id, run: run})
+	q.cond.Broadcast()
+	return nil
+}
+
+// GetTriggerQueueStatus returns the depth and the counters of the queue
+func GetTriggerQueueStatus() models.TriggerQueueStatus {
+	q := triggers
+	if q == nil {
+		return models.TriggerQueueStatus{}
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return models.TriggerQueueStatus{
+		Workers:  q.workers,
+		Capacity: q.capacity,
+		Overflow: q.overflow,
+		Depth:    len(q.pending),
+		Running:  len(q.running),
+		Dropped:  q.dropped,
+		Rejected: q.rejected,
+	}
+}
+
+// acquireRule takes the running slot of the rule for a manual execution, so it never runs along with
+// a callback of the rule. It waits for the running callback until the timeout, then the rule is busy
+// and a LimitExceeded error is returned. The returned function releases the slot
+func acquireRule(ruleId string, timeout time.Duration) (func(), errors.EdgeX) {
+	q := triggers
+	if q == nil {
+		return nil, errors.NewCommonEdgeX(errors.KindServiceUnavailable, "trigger queue is not started", nil)
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	expired := false
+	timer := time.AfterFunc(timeout, func() {
+		q.mutex.Lock()
+		expired = true
+		q.cond.Broadcast()
+		q.mutex.Unlock()
+	})
+	defer timer.Stop()
+
+	for {
+		if q.stopped {
+			return nil, errors.NewCommonEdgeX(errors.KindServiceUnavailable, "trigger queue is stopped", nil)
+		}
+		if !q.running[ruleId] {
+			break
+		}
+		if expired {
+			return nil, errors.NewCommonEdgeX(errors.KindLimitExceeded, fmt.Sprintf("rule with id '%s' is still running after %s", ruleId, timeout), nil)
+		}
+		q.cond.Wait()
+	}
+	q.running[ruleId] = true
+
+	return func() {
+		q.mutex.Lock()
+		delete(q.running, ruleId)
+		q.cond.Broadcast()
+		q.mutex.Unlock()
+	}, nil
+}
+
+func (q *triggerQueue) work() {
+	for {
+		t, ok := q.next()
+		if !ok {
+			return
+		}
+		t.run()
+
+		q.mutex.Lock()
+		delete(q.running, t.ruleId)
+		q.cond.Broadcast()
+		q.mutex.Unlock()
+	}
+}
+
+// next waits for the oldest callback of a rule which is not running, it returns false when the queue is stopped
+func (q *triggerQueue) next() (queuedTrigger, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for {
+		if q.stopped {
+			return queuedTrigger{}, false
+		}
+		for i, t := range q.pending {
+			if q.running[t.ruleId] {
+				continue
+			}
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			q.running[t.ruleId] = true
+			return t, true
+		}
+		q.cond.Wait()
+	}
+}
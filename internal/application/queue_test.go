@@ -0,0 +1,153 @@
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+func TestEnqueueTriggerOverflow(t *testing.T) {
+	tests := []struct {
+		name         string
+		overflow     string
+		wantKind     errors.ErrKind
+		wantPending  []string
+		wantDropped  uint64
+		wantRejected uint64
+	}{
+		{
+			name:         "reject",
+			overflow:     cm.RejectOverflow,
+			wantKind:     errors.KindLimitExceeded,
+			wantPending:  []string{"rule-1", "rule-2"},
+			wantRejected: 1,
+		},
+		{
+			name:        "drop oldest",
+			overflow:    cm.DropOldestOverflow,
+			wantPending: []string{"rule-2", "rule-3"},
+			wantDropped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// no workers, the triggers stay queued
+			initTriggerQueue(0, 2, tt.overflow)
+			defer func() {
+				stopTriggerQueue()
+				triggers = nil
+			}()
+
+			index, state := 0, true
+			content := models.ContentTrigger{TriggerIndex: &index, TriggerState: &state}
+			for _, id := range []string{"rule-1", "rule-2"} {
+				if err := EnqueueTrigger(id, content); err != nil {
+					t.Fatalf("EnqueueTrigger(%s) unexpected error: %v", id, err)
+				}
+			}
+
+			err := EnqueueTrigger("rule-3", content)
+			if tt.wantKind == "" && err != nil {
+				t.Fatalf("EnqueueTrigger(rule-3) unexpected error: %v", err)
+			}
+			if tt.wantKind != "" && (err == nil || errors.Kind(err) != tt.wantKind) {
+				t.Fatalf("EnqueueTrigger(rule-3) error = %v, want kind %s", err, tt.wantKind)
+			}
+
+			status := GetTriggerQueueStatus()
+			if status.Depth != len(tt.wantPending) || status.Dropped != tt.wantDropped || status.Rejected != tt.wantRejected {
+				t.Errorf("status = %+v, want depth %d, dropped %d, rejected %d",
+					status, len(tt.wantPending), tt.wantDropped, tt.wantRejected)
+			}
+			for i, id := range tt.wantPending {
+				if got := triggers.pending[i].ruleId; got != id {
+					t.Errorf("pending[%d] = %s, want %s", i, got, id)
+				}
+			}
+		})
+	}
+}
+
+func TestEnqueueTriggerStopped(t *testing.T) {
+	triggers = nil
+	index, state := 0, true
+	content := models.ContentTrigger{TriggerIndex: &index, TriggerState: &state}
+	if err := EnqueueTrigger("rule-1", content); errors.Kind(err) != errors.KindServiceUnavailable {
+		t.Errorf("EnqueueTrigger() without a queue error = %v, want kind %s", err, errors.KindServiceUnavailable)
+	}
+
+	initTriggerQueue(0, 2, cm.RejectOverflow)
+	stopTriggerQueue()
+	defer func() { triggers = nil }()
+	if err := EnqueueTrigger("rule-1", content); errors.Kind(err) != errors.KindServiceUnavailable {
+		t.Errorf("EnqueueTrigger() on a stopped queue error = %v, want kind %s", err, errors.KindServiceUnavailable)
+	}
+}
+
+func TestAcquireRuleWaitsForTheRunningCallback(t *testing.T) {
+	// no workers, the test takes the slots as a worker would
+	initTriggerQueue(0, 2, cm.RejectOverflow)
+	defer func() {
+		stopTriggerQueue()
+		triggers = nil
+	}()
+
+	index, state := 0, true
+	content := models.ContentTrigger{TriggerIndex: &index, TriggerState: &state}
+	if err := EnqueueTrigger("rule-1", content); err != nil {
+		t.Fatalf("EnqueueTrigger() unexpected error: %v", err)
+	}
+	callback, _ := triggers.next()
+
+	if _, err := acquireRule("rule-1", 20*time.Millisecond); errors.Kind(err) != errors.KindLimitExceeded {
+		t.Fatalf("acquireRule() of a running rule error = %v, want kind %s", err, errors.KindLimitExceeded)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		release, err := acquireRule("rule-1", 5*time.Second)
+		if err != nil {
+			t.Errorf("acquireRule() unexpected error: %v", err)
+		}
+		acquired <- release
+	}()
+	select {
+	case <-acquired:
+		t.Fatalf("slot of rule-1 acquired while its callback is running")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// the worker is done with the callback
+	triggers.mutex.Lock()
+	delete(triggers.running, callback.ruleId)
+	triggers.cond.Broadcast()
+	triggers.mutex.Unlock()
+
+	release := <-acquired
+	if status := GetTriggerQueueStatus(); status.Running != 1 {
+		t.Errorf("status = %+v, want the manual execution running", status)
+	}
+	// the next callback of the rule waits for the manual execution
+	if err := EnqueueTrigger("rule-1", content); err != nil {
+		t.Fatalf("EnqueueTrigger() unexpected error: %v", err)
+	}
+	next := make(chan queuedTrigger)
+	go func() {
+		queued, _ := triggers.next()
+		next <- queued
+	}()
+	select {
+	case <-next:
+		t.Fatalf("callback of rule-1 started during the manual execution")
+	case <-time.After(20 * time.Millisecond):
+	}
+	release()
+	if queued := <-next; queued.ruleId != "rule-1" {
+		t.Errorf("next callback of %s, want rule-1", queued.ruleId)
+	}
+}
@@ -0,0 +1,177 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rddigital/device-scenario/internal/cache"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+type runningRamp struct {
+	ruleId string
+	cancel context.CancelFunc
+}
+
+type rampManager struct {
+	ramps   map[string]*runningRamp // key is "{remote}/{deviceName}/{commandName}"
+	running sync.WaitGroup
+	mutex   sync.Mutex
+}
+
+var (
+	ramps = &rampManager{
+		ramps: make(map[string]*runningRamp),
+	}
+)
+
+// executeRampAction computes the start value then writes the intermediate values in the background,
+// a new ramp on the same device command replaces the running one. Each write is cancelled after the step timeout
+func executeRampAction(ctx context.Context, ramp *models.RampAction, stepTimeout time.Duration, tc triggerContext) error {
+	if ramp == nil {
+		return fmt.Errorf("no ramp specified")
+	}
+
+	duration, err := time.ParseDuration(ramp.Duration)
+	if err != nil {
+		return fmt.Errorf("parse duration '%s' error: %s", ramp.Duration, err.Error())
+	}
+
+	resourceName := ramp.ResourceName
+	if resourceName == "" {
+		resourceName = ramp.CommandName
+	}
+
+	var start float64
+	if ramp.Start != nil {
+		start = *ramp.Start
+	} else {
+		value, err := tc.executor.readResource(ctx, ramp.Remote, ramp.DeviceName, resourceName)
+		if err != nil {
+			return fmt.Errorf("read start value error: %s", err.Error())
+		}
+		if start, err = strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("start value '%s' is not a number", value)
+		}
+	}
+
+	tc.executor.startRamp(tc.RuleId, ramp, resourceName, start, duration, stepTimeout)
+	return nil
+}
+
+// startRamp writes the intermediate values of the ramp in the background
+func startRamp(ruleId string, ramp *models.RampAction, resourceName string, start float64, duration time.Duration, stepTimeout time.Duration) {
+	key := ramp.Remote + "/" + ramp.DeviceName + "/" + ramp.CommandName
+	rampCtx, cancel := context.WithCancel(context.Background())
+	current := &runningRamp{
+		ruleId: ruleId,
+		cancel: cancel,
+	}
+
+	ramps.mutex.Lock()
+	if previous, ok := ramps.ramps[key]; ok {
+		previous.cancel()
+	}
+	ramps.ramps[key] = current
+	ramps.running.Add(1)
+	ramps.mutex.Unlock()
+
+	go func() {
+		defer ramps.running.Done()
+		defer func() {
+			ramps.mutex.Lock()
+			if ramps.ramps[key] == current {
+				delete(ramps.ramps, key)
+			}
+			ramps.mutex.Unlock()
+			cancel()
+		}()
+		var interval time.Duration
+		if ramp.Steps > 0 {
+			interval = duration / time.Duration(ramp.Steps)
+		}
+		runRamp(rampCtx, ruleId, ramp, resourceName, start, interval, stepTimeout)
+	}()
+}
+
+func runRamp(ctx context.Context, ruleId string, ramp *models.RampAction, resourceName string, start float64, interval time.Duration, stepTimeout time.Duration) {
+	if ramp.Steps <= 0 || interval <= 0 {
+		lc.Errorf("ramp of command '%s' to device '%s' has an invalid interval %s for %d steps", ramp.CommandName, ramp.DeviceName, interval, ramp.Steps)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for step := 1; step <= ramp.Steps; step++ {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			lc.Debugf("ramp of command '%s' to device '%s' cancelled at step %d", ramp.CommandName, ramp.DeviceName, step)
+			return
+		}
+
+		value := start + (ramp.End-start)*float64(step)/float64(ramp.Steps)
+		bodyParam := map[string]string{resourceName: strconv.FormatFloat(value, 'f', ramp.Decimals, 64)}
+		rule, ok := cache.Rules().ForId(ruleId)
+		if !ok {
+			lc.Debugf("rule with id '%s' removed -> ramp of command '%s' to device '%s' stopped at step %d", ruleId, ramp.CommandName, ramp.DeviceName, step)
+			return
+		}
+		if IsPaused(rule.Name) {
+			lc.Debugf("automations paused -> ramp of command '%s' to device '%s' stopped at step %d", ramp.CommandName, ramp.DeviceName, step)
+			return
+		}
+		write := commandWrite{remote: ramp.Remote, deviceName: ramp.DeviceName, commandName: ramp.CommandName, params: bodyParam}
+		if other, ok := arbitrate(ruleId, rule.PriorityValue(), write); ok {
+			lc.Debugf("ramp of command '%s' to device '%s' step %d overridden by active rule '%s'", ramp.CommandName, ramp.DeviceName, step, other.Name)
+			continue
+		}
+		if err := writeRampStep(ctx, ramp, bodyParam, stepTimeout); err != nil {
+			lc.Errorf("ramp of command '%s' to device '%s' step %d error: %s", ramp.CommandName, ramp.DeviceName, step, err.Error())
+		}
+	}
+}
+
+// writeRampStep sends the value of a step, a device which does not answer does not hold the following steps
+func writeRampStep(rampCtx context.Context, ramp *models.RampAction, bodyParam map[string]string, stepTimeout time.Duration) error {
+	cc, err := commandClientFor(ramp.Remote)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(rampCtx, stepTimeout)
+	defer cancel()
+
+	if _, edgexErr := cc.IssueSetCommandByName(ctx, ramp.DeviceName, ramp.CommandName, bodyParam); edgexErr != nil {
+		return edgexErr
+	}
+	return nil
+}
+
+// cancelRuleRamps stops the running ramps started by the rule
+func cancelRuleRamps(ruleId string) {
+	ramps.mutex.Lock()
+	defer ramps.mutex.Unlock()
+
+	for key, r := range ramps.ramps {
+		if r.ruleId == ruleId {
+			r.cancel()
+			delete(ramps.ramps, key)
+		}
+	}
+}
+
+// stopRamps stops all the running ramps and waits for their last step
+func stopRamps() {
+	ramps.mutex.Lock()
+	for key, r := range ramps.ramps {
+		r.cancel()
+		delete(ramps.ramps, key)
+	}
+	ramps.mutex.Unlock()
+
+	ramps.running.Wait()
+}
@@ -0,0 +1,178 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ctModels "github.com/edgexfoundry/go-mod-core-contracts/v2/models"
+
+	"github.com/rddigital/device-scenario/internal/cache"
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// waitForSets waits until the fake core-command received n writes
+func waitForSets(t *testing.T, cc *fakeCoreCommand, n int) []setCommand {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if sets := cc.received(); len(sets) >= n {
+			return sets
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("%d writes received, want %d", len(cc.received()), n)
+	return nil
+}
+
+func TestExecuteRampAction(t *testing.T) {
+	loadRules(t, models.Rule{Id: "sunrise", Name: "sunrise"})
+	cc := newFakeCoreCommand()
+	cc.setValue("dimmer", "level", "20")
+	commandClient = cc
+	defer func() { commandClient = nil }()
+
+	ramp := &models.RampAction{DeviceName: "dimmer", CommandName: "level", End: 60, Duration: "40ms", Steps: 4}
+	if err := executeRampAction(context.Background(), ramp, actionTimeout, triggerContext{RuleId: "sunrise", executor: liveExecutor{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sets := waitForSets(t, cc, 4)
+	want := []string{"30", "40", "50", "60"}
+	for i, set := range sets {
+		if set.settings["level"] != want[i] {
+			t.Errorf("step %d wrote %v, want level %s", i+1, set.settings, want[i])
+		}
+	}
+}
+
+func TestCancelRuleRamps(t *testing.T) {
+	loadRules(t, models.Rule{Id: "sunrise", Name: "sunrise"})
+	cc := newFakeCoreCommand()
+	commandClient = cc
+	defer func() { commandClient = nil }()
+
+	start := 0.0
+	ramp := &models.RampAction{DeviceName: "dimmer", CommandName: "level", Start: &start, End: 100, Duration: "1s", Steps: 100}
+	if err := executeRampAction(context.Background(), ramp, actionTimeout, triggerContext{RuleId: "sunrise", executor: liveExecutor{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForSets(t, cc, 2)
+
+	cancelRuleRamps("sunrise")
+	stopped := len(cc.received())
+	time.Sleep(50 * time.Millisecond)
+	// one step may be written while the ramp is cancelled
+	if n := len(cc.received()); n > stopped+1 {
+		t.Errorf("%d steps written after the cancellation", n-stopped)
+	}
+	if n := len(cc.received()); n >= ramp.Steps {
+		t.Errorf("ramp ran to its end")
+	}
+}
+
+func TestExecuteRampActionReplacesRunningRamp(t *testing.T) {
+	loadRules(t, models.Rule{Id: "sunrise", Name: "sunrise"}, models.Rule{Id: "sunset", Name: "sunset"})
+	cc := newFakeCoreCommand()
+	commandClient = cc
+	defer func() { commandClient = nil }()
+	defer cancelRuleRamps("sunset")
+
+	start := 0.0
+	up := &models.RampAction{DeviceName: "dimmer", CommandName: "level", Start: &start, End: 100, Duration: "1s", Steps: 100}
+	if err := executeRampAction(context.Background(), up, actionTimeout, triggerContext{RuleId: "sunrise", executor: liveExecutor{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForSets(t, cc, 1)
+
+	high := 100.0
+	down := &models.RampAction{DeviceName: "dimmer", CommandName: "level", Start: &high, End: 0, Duration: "20ms", Steps: 2}
+	if err := executeRampAction(context.Background(), down, actionTimeout, triggerContext{RuleId: "sunset", executor: liveExecutor{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	sets := cc.received()
+	if last := sets[len(sets)-1].settings["level"]; last != "0" {
+		t.Errorf("last value written %s, want 0 from the replacing ramp", last)
+	}
+}
+
+func TestExecuteRampActionSkipsOverriddenSteps(t *testing.T) {
+	priority := 5
+	boost := models.Rule{
+		Id:         "boost",
+		Name:       "boost",
+		AdminState: ctModels.Unlocked,
+		Priority:   &priority,
+		Conditions: []models.Condition{temperature(cm.AndLogic, "thermostat", ">", "30")},
+		Actions:    []models.Action{{DeviceName: "dimmer", CommandName: "level", Body: `{"level":"100"}`}},
+	}
+	loadRules(t, boost, models.Rule{Id: "sunrise", Name: "sunrise", AdminState: ctModels.Unlocked})
+	cache.Rules().UpdateStateRule(boost.Id, 0, true, "thermostat")
+
+	cc := newFakeCoreCommand()
+	commandClient = cc
+	defer func() { commandClient = nil }()
+
+	start := 0.0
+	ramp := &models.RampAction{DeviceName: "dimmer", CommandName: "level", Start: &start, End: 60, Duration: "40ms", Steps: 4}
+	if err := executeRampAction(context.Background(), ramp, actionTimeout, triggerContext{RuleId: "sunrise", executor: liveExecutor{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if sets := cc.received(); len(sets) != 0 {
+		t.Errorf("ramp wrote %v while overridden by the active rule", sets)
+	}
+}
+
+func TestExecuteRampActionStepTimeout(t *testing.T) {
+	loadRules(t, models.Rule{Id: "sunrise", Name: "sunrise"})
+	cc := newFakeCoreCommand()
+	cc.hang("dimmer")
+	commandClient = cc
+	defer func() { commandClient = nil }()
+	defer cancelRuleRamps("sunrise")
+	time.AfterFunc(50*time.Millisecond, func() { cc.answer("dimmer") })
+
+	start := 0.0
+	ramp := &models.RampAction{DeviceName: "dimmer", CommandName: "level", Start: &start, End: 100, Duration: "200ms", Steps: 10}
+	if err := executeRampAction(context.Background(), ramp, 20*time.Millisecond, triggerContext{RuleId: "sunrise", executor: liveExecutor{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the steps sent while the dimmer does not answer are abandoned, the following ones are written
+	sets := waitForSets(t, cc, 1)
+	if last := sets[len(sets)-1].settings["level"]; last == "10" {
+		t.Errorf("first step written, want it abandoned")
+	}
+	sets = waitForSets(t, cc, len(sets)+1)
+	if len(sets) >= ramp.Steps {
+		t.Errorf("%d steps written, want the first ones abandoned", len(sets))
+	}
+}
+
+func TestRampStopsWhenTheRuleIsRemoved(t *testing.T) {
+	loadRules(t, models.Rule{Id: "sunrise", Name: "sunrise"})
+	cc := newFakeCoreCommand()
+	commandClient = cc
+	defer func() { commandClient = nil }()
+	defer cancelRuleRamps("sunrise")
+
+	start := 0.0
+	ramp := &models.RampAction{DeviceName: "dimmer", CommandName: "level", Start: &start, End: 100, Duration: "1s", Steps: 100}
+	if err := executeRampAction(context.Background(), ramp, actionTimeout, triggerContext{RuleId: "sunrise", executor: liveExecutor{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForSets(t, cc, 1)
+
+	cache.Rules().RemoveByName("sunrise")
+	stopped := len(cc.received())
+	time.Sleep(50 * time.Millisecond)
+	// one step may be written while the rule is removed
+	if n := len(cc.received()); n > stopped+1 {
+		t.Errorf("%d steps written after the rule was removed", n-stopped)
+	}
+}
@@ -0,0 +1,77 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/interfaces"
+	ctCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+)
+
+// readResourceValueWith issues a GET command on the resource of the device through the core-command
+// client cc and returns the value of its reading
+func readResourceValueWith(ctx context.Context, cc interfaces.CommandClient, deviceName string, resourceName string) (string, error) {
+	response, edgexErr := cc.IssueGetCommandByName(ctx, deviceName, resourceName, ctCommon.ValueNo, ctCommon.ValueYes)
+	if edgexErr != nil {
+		return "", edgexErr
+	}
+	if response == nil {
+		return "", fmt.Errorf("no event returned by device '%s'", deviceName)
+	}
+
+	for _, reading := range response.Event.Readings {
+		if reading.ResourceName == resourceName {
+			return reading.Value, nil
+		}
+	}
+	return "", fmt.Errorf("no reading of resource '%s' returned by device '%s'", resourceName, deviceName)
+}
+
+// equalValues compares two values as numbers or booleans if possible, as strings otherwise
+func equalValues(a string, b string) bool {
+	if fa, err := strconv.ParseFloat(a, 64); err == nil {
+		if fb, err := strconv.ParseFloat(b, 64); err == nil {
+			return fa == fb
+		}
+	}
+	if ba, err := strconv.ParseBool(a); err == nil {
+		if bb, err := strconv.ParseBool(b); err == nil {
+			return ba == bb
+		}
+	}
+	return a == b
+}
+
+// compareValues applies the operator to the value and the expected value, the values are compared
+// as numbers if possible, only the equality operators are supported for the other values
+func compareValues(value string, operator string, expected string) (bool, error) {
+	switch operator {
+	case "=":
+		return equalValues(value, expected), nil
+	case "!=":
+		return !equalValues(value, expected), nil
+	}
+
+	fv, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false, fmt.Errorf("value '%s' is not a number", value)
+	}
+	fe, err := strconv.ParseFloat(expected, 64)
+	if err != nil {
+		return false, fmt.Errorf("expected value '%s' is not a number", expected)
+	}
+
+	switch operator {
+	case ">":
+		return fv > fe, nil
+	case ">=":
+		return fv >= fe, nil
+	case "<":
+		return fv < fe, nil
+	case "<=":
+		return fv <= fe, nil
+	default:
+		return false, fmt.Errorf("unknown operator '%s'", operator)
+	}
+}
@@ -2,14 +2,12 @@ package application
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"reflect"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/edgexfoundry/device-sdk-go/v2/pkg/service"
-	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/http"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/interfaces"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
 	ctCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
@@ -18,59 +16,87 @@ import (
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
 	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
 	ctModels "github.com/edgexfoundry/go-mod-core-contracts/v2/models"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
 
 	"github.com/google/uuid"
 	"github.com/rddigital/device-scenario/internal/cache"
 	"github.com/rddigital/device-scenario/internal/client"
 	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/config"
 	"github.com/rddigital/device-scenario/internal/models"
+	"github.com/rddigital/device-scenario/internal/store"
 )
 
 var (
-	host                 string
-	port                 int
-	lc                   logger.LoggingClient
-	commandClient        interfaces.CommandClient
-	intervalClient       interfaces.IntervalClient
-	intervalActionClient interfaces.IntervalActionClient
-	notificationClient   interfaces.NotificationClient
-	ruleEngineClient     client.RuleEngineClient
+	host               string
+	port               int
+	lc                 logger.LoggingClient
+	commandClient      interfaces.CommandClient
+	metadataClient     client.MetadataClient
+	schedulerClient    client.SchedulerClient
+	notificationClient client.NotificationClient
+	ruleEngineClient   client.RuleEngineClient
+	webhookClient      client.WebhookClient
+	messageBusClient   client.MessageBusClient
+	// remoteCommandClients are the core-command of other EdgeX instances by name
+	remoteCommandClients map[string]interfaces.CommandClient
+
+	actionTimeout  time.Duration
+	ruleTimeout    time.Duration
+	requestTimeout time.Duration
 )
 
 const (
 	StreamName         = "events"
 	StreamSQLTemplate  = string(`{"sql":"create stream %s () WITH (FORMAT=\"JSON\", TYPE=\"edgex\")"}`)
-	AddRuleSQLTemplate = string(`{"id":"%s","sql":"SELECT (collect(%s)[1] %s %s) as v FROM %s GROUP BY PADCOUNTWINDOW(2,1) FILTER(WHERE meta(deviceName) = \"%s\") HAVING collect(%s)[0]  %s %s OR collect(%s)[1] %s %s",
+	AddRuleSQLTemplate = string(`{"id":"%s","sql":"SELECT (collect(%s)[1] %s %s) as v, collect(%s)[1] as r FROM %s GROUP BY PADCOUNTWINDOW(2,1) FILTER(WHERE meta(deviceName) = \"%s\") HAVING collect(%s)[0]  %s %s OR collect(%s)[1] %s %s",
 	"actions": [{
 		"rest": {
 			"url": "http://%s:%d/api/v2/rule/id/%s",
 			"method": "post",
-			"dataTemplate": "{\"triggerState\":{{.v}},\"triggerIndex\":%d}",
+			"dataTemplate": "{\"triggerState\":{{.v}},\"triggerIndex\":%d,\"triggerValue\":{{json .r}}}",
 			"sendSingle": true
 		  }
 		}
 	]}`)
-	UpdateRuleSQLTemplate = string(`{"sql":"SELECT (collect(%s)[1] %s %s) as v FROM %s GROUP BY PADCOUNTWINDOW(2,1) FILTER(WHERE meta(deviceName) = \"%s\") HAVING collect(%s)[0]  %s %s OR collect(%s)[1] %s %s",
+	UpdateRuleSQLTemplate = string(`{"sql":"SELECT (collect(%s)[1] %s %s) as v, collect(%s)[1] as r FROM %s GROUP BY PADCOUNTWINDOW(2,1) FILTER(WHERE meta(deviceName) = \"%s\") HAVING collect(%s)[0]  %s %s OR collect(%s)[1] %s %s",
 	"actions": [{
 		"rest": {
 			"url": "http://%s:%d/api/v2/rule/id/%s",
 			"method": "post",
-			"dataTemplate": "{\"triggerState\":{{.v}},\"triggerIndex\":%d}",
+			"dataTemplate": "{\"triggerState\":{{.v}},\"triggerIndex\":%d,\"triggerValue\":{{json .r}}}",
 			"sendSingle": true
 		  }
 		}
 	]}`)
 )
 
-func InitRuleApplication(l logger.LoggingClient, portService int, hostService, urlCoreCommand, urlNotification, urlSchduler, urlRuleEngine string) error {
+func InitRuleApplication(l logger.LoggingClient, portService int, hostService, urlCoreCommand, urlNotification, urlSchduler, urlRuleEngine, urlMetadata string,
+	messageBusConfig *types.MessageBusConfig, storagePath string, remoteCommands map[string]config.RemoteCommandInfo, executionInfo config.ExecutionInfo,
+	historyInfo config.HistoryInfo, triggerQueueInfo config.TriggerQueueInfo, stormInfo config.StormInfo) error {
 	lc = l
 	host = hostService
 	port = portService
-	commandClient = http.NewCommandClient(urlCoreCommand)
-	notificationClient = http.NewNotificationClient(urlNotification)
-	intervalClient = http.NewIntervalClient(urlSchduler)
-	intervalActionClient = http.NewIntervalActionClient(urlNotification)
-	ruleEngineClient = client.NewKuiperRuleClient(urlRuleEngine)
+	actionTimeout = durationOrDefault(executionInfo.ActionTimeout, cm.DefaultActionTimeout)
+	ruleTimeout = durationOrDefault(executionInfo.RuleTimeout, cm.DefaultRuleTimeout)
+	requestTimeout = durationOrDefault(executionInfo.RequestTimeout, cm.DefaultRequestTimeout)
+	commandClient = client.NewHttpCommandClient(urlCoreCommand, nil)
+	metadataClient = client.NewHttpMetadataClient(urlMetadata)
+	notificationClient = client.NewHttpNotificationClient(urlNotification)
+	schedulerClient = client.NewHttpSchedulerClient(urlSchduler)
+	ruleEngineClient = client.NewKuiperRuleClient(urlRuleEngine, requestTimeout)
+	webhookClient = client.NewHttpWebhookClient(cm.DefaultWebhookTimeout)
+	if messageBusConfig != nil {
+		messageBusClient = client.NewMessageBusClient(*messageBusConfig)
+	}
+	remoteCommandClients = make(map[string]interfaces.CommandClient, len(remoteCommands))
+	for name, remote := range remoteCommands {
+		headers := make(map[string]string)
+		if remote.AuthHeaderName != "" {
+			headers[remote.AuthHeaderName] = remote.AuthHeaderValue
+		}
+		remoteCommandClients[name] = client.NewHttpCommandClient(strings.TrimSuffix(remote.BaseUrl, "/"), headers)
+	}
 
 	_, err := ruleEngineClient.DescribeStream(StreamName)
 	if err != nil {
@@ -81,34 +107,81 @@ func InitRuleApplication(l logger.LoggingClient, portService int, hostService, u
 			return err
 		}
 	}
-	cache.InitCache()
+	if err = store.InitStore(storagePath); err != nil {
+		return err
+	}
+	cache.InitCache(lc)
+	for _, rule := range cache.Rules().All() {
+		ruleWrites.set(rule)
+	}
 	sysnRule()
+	initPause()
+	initTimers()
+	initSnapshots()
+	maxRecords := historyInfo.MaxRecords
+	if maxRecords == 0 {
+		maxRecords = cm.DefaultHistoryMaxRecords
+	}
+	initHistory(maxRecords, durationOrDefault(historyInfo.MaxAge, cm.DefaultHistoryMaxAge))
+
+	workers := triggerQueueInfo.Workers
+	if workers == 0 {
+		workers = cm.DefaultTriggerWorkers
+	}
+	queueSize := triggerQueueInfo.QueueSize
+	if queueSize == 0 {
+		queueSize = cm.DefaultTriggerQueueSize
+	}
+	overflow := triggerQueueInfo.Overflow
+	if overflow == "" {
+		overflow = cm.DropOldestOverflow
+	}
+	initTriggerQueue(workers, queueSize, overflow)
+
+	maxFires := stormInfo.MaxFiresPerMinute
+	if maxFires == 0 {
+		maxFires = cm.DefaultStormMaxFiresPerMinute
+	}
+	initStormBreaker(maxFires)
 
 	return nil
 }
 
+func StopRuleApplication() {
+	stopTriggerQueue()
+	stopRamps()
+	flushTimers()
+	flushHistory()
+	cache.Flush()
+	if messageBusClient != nil {
+		if err := messageBusClient.Disconnect(); err != nil {
+			lc.Errorf(err.Error())
+		}
+	}
+}
+
 // remove all intervals, interval actions, rule engines that do not belong any rules
 func sysnRule() {
 	ctx := context.Background()
 	// sysn intervals
-	intervals, err := intervalClient.AllIntervals(ctx, 0, cm.DefaultLimit)
+	intervals, err := schedulerClient.AllIntervals(ctx, 0, cm.DefaultLimit)
 	if err != nil {
 		for _, i := range intervals.Intervals {
 			if id := parseName(i.Name); id != "" {
 				if !cache.Rules().CheckExistsById(id) {
-					intervalClient.DeleteIntervalByName(ctx, i.Name)
+					schedulerClient.DeleteIntervalByName(ctx, i.Name)
 				}
 			}
 		}
 	}
 
 	// sysn interval actions
-	intervalActions, err := intervalActionClient.AllIntervalActions(ctx, 0, cm.DefaultLimit)
+	intervalActions, err := schedulerClient.AllIntervalActions(ctx, 0, cm.DefaultLimit)
 	if err != nil {
 		for _, i := range intervalActions.Actions {
 			if id := parseName(i.Name); id != "" {
 				if !cache.Rules().CheckExistsById(id) {
-					intervalActionClient.DeleteIntervalActionByName(ctx, i.Name)
+					schedulerClient.DeleteIntervalActionByName(ctx, i.Name)
 				}
 			}
 		}
@@ -135,7 +208,8 @@ func parseName(name string) string {
 	return arrStr[0]
 }
 
-func AddRule(rule models.Rule) errors.EdgeX {
+// AddRule adds the rule and returns the warnings about its possible conflicts with the other rules
+func AddRule(rule models.Rule) ([]string, errors.EdgeX) {
 	if rule.Id == "" {
 		id, _ := uuid.NewUUID()
 		rule.Id = id.String()
@@ -144,46 +218,46 @@ func AddRule(rule models.Rule) errors.EdgeX {
 	if _, ok := cache.Rules().ForName(rule.Name); ok {
 		err := fmt.Errorf("rule '%s' already exists", rule.Name)
 		lc.Error(err.Error())
-		return errors.NewCommonEdgeXWrapper(err)
+		return nil, errors.NewCommonEdgeXWrapper(err)
 	}
 
 	// Alway unlock rule when change conditions
 	rule.AdminState = ctModels.Unlocked
 
+	if err := validateActions(rule, nil); err != nil {
+		err = fmt.Errorf("add rule '%s' error: %s", rule.Name, err.Error())
+		lc.Error(err.Error())
+		return nil, errors.NewCommonEdgeX(errors.KindContractInvalid, err.Error(), nil)
+	}
+
 	lc.Debugf("adding rule: %s", rule.Name)
 
 	err := addRuleConditions(rule)
 	if err != nil {
 		err = fmt.Errorf("add rule condition '%s' error: %s", rule.Name, err.Error())
 		lc.Error(err.Error())
-		return errors.NewCommonEdgeXWrapper(err)
+		return nil, errors.NewCommonEdgeXWrapper(err)
 	}
 	lc.Debugf("add rule conditions '%s' success", rule.Name)
 
-	properties := models.RuleToProperties(rule)
-	var device = ctModels.Device{
-		Id:             rule.Id,
-		Name:           rule.Name,
-		Description:    rule.Description,
-		AdminState:     rule.AdminState,
-		OperatingState: ctModels.Up,
-		Protocols:      properties,
-		ProfileName:    cm.AutoScenarioProfile,
-		ServiceName:    cm.DeviceServiceName,
-	}
-
+	device := ruleDevice(rule)
 	ds := service.RunningService()
 	_, err = ds.AddDevice(device)
 	if err != nil {
 		err = fmt.Errorf("add rule '%s' to database error: %s", rule.Name, err.Error())
 		lc.Error(err.Error())
-		return errors.NewCommonEdgeXWrapper(err)
+		return nil, errors.NewCommonEdgeXWrapper(err)
 	}
 
 	cache.Rules().Add(rule)
+	ruleWrites.set(rule)
 	lc.Debugf("add rule '%s' success", rule.Name)
 
-	return nil
+	warnings := append(detectConflicts(rule), detectLoops(rule)...)
+	for _, w := range warnings {
+		lc.Warnf("add rule '%s': %s", rule.Name, w)
+	}
+	return warnings, nil
 }
 
 func GetAllRule() []models.Rule {
@@ -198,12 +272,13 @@ func GetRuleByName(name string) (models.Rule, errors.EdgeX) {
 	return rule, nil
 }
 
-func UpdateRuleByName(name string, rule models.Rule) errors.EdgeX {
+// UpdateRuleByName updates the rule and returns the warnings about its possible conflicts with the other rules
+func UpdateRuleByName(name string, rule models.Rule) ([]string, errors.EdgeX) {
 	oldRule, ok := cache.Rules().ForName(name)
 	if !ok {
 		err := fmt.Errorf("rule with id '%s' does not exists", oldRule.Id)
 		lc.Error(err.Error())
-		return errors.NewCommonEdgeXWrapper(err)
+		return nil, errors.NewCommonEdgeXWrapper(err)
 	}
 
 	rule.Id = oldRule.Id
@@ -219,6 +294,15 @@ func UpdateRuleByName(name string, rule models.Rule) errors.EdgeX {
 	if rule.NotifyEnable == "" {
 		rule.NotifyEnable = oldRule.NotifyEnable
 	}
+	if rule.Notification == nil {
+		rule.Notification = oldRule.Notification
+	}
+	if rule.Timeout == "" {
+		rule.Timeout = oldRule.Timeout
+	}
+	if rule.Priority == nil {
+		rule.Priority = oldRule.Priority
+	}
 	if len(rule.Actions) == 0 {
 		rule.Actions = oldRule.Actions
 	}
@@ -226,6 +310,12 @@ func UpdateRuleByName(name string, rule models.Rule) errors.EdgeX {
 		rule.Conditions = oldRule.Conditions
 	}
 
+	if err := validateActions(rule, oldRule.Actions); err != nil {
+		err = fmt.Errorf("update rule with id '%s' error: %s", rule.Id, err.Error())
+		lc.Error(err.Error())
+		return nil, errors.NewCommonEdgeX(errors.KindContractInvalid, err.Error(), nil)
+	}
+
 	lc.Debugf("updating rule with id '%s'", rule.Id)
 
 	var forceUpdate bool = false
@@ -243,35 +333,72 @@ func UpdateRuleByName(name string, rule models.Rule) errors.EdgeX {
 		if err != nil {
 			err = fmt.Errorf("update rule condition with id '%s' error: %s", rule.Id, err.Error())
 			lc.Error(err.Error())
-			return errors.NewCommonEdgeXWrapper(err)
+			return nil, errors.NewCommonEdgeXWrapper(err)
 		}
 		lc.Debugf("update rule conditions with id '%s' success", rule.Id)
 	}
 
-	properties := models.RuleToProperties(rule)
-	var device = ctModels.Device{
-		Id:             rule.Id,
-		Name:           rule.Name,
-		Description:    rule.Description,
-		AdminState:     rule.AdminState,
-		OperatingState: ctModels.Up,
-		Protocols:      properties,
-		ProfileName:    cm.AutoScenarioProfile,
-		ServiceName:    cm.DeviceServiceName,
-	}
-
+	device := ruleDevice(rule)
 	ds := service.RunningService()
 	err := ds.UpdateDevice(device)
 	if err != nil {
 		err = fmt.Errorf("update rule with id '%s' in database error: %s", rule.Id, err.Error())
 		lc.Error(err.Error())
-		return errors.NewCommonEdgeXWrapper(err)
+		return nil, errors.NewCommonEdgeXWrapper(err)
 	}
 
 	cache.Rules().Update(rule) // update rule and reset states
+	ruleWrites.set(rule)
+	cancelRuleTimers(rule.Id, false)
+	cancelRuleRamps(rule.Id)
+	if rule.AdminState == ctModels.Locked {
+		cancelRuleExecutions(rule.Id)
+	}
 	lc.Debugf("update rule with id '%s' success", rule.Id)
 
-	return nil
+	warnings := append(detectConflicts(rule), detectLoops(rule)...)
+	for _, w := range warnings {
+		lc.Warnf("update rule '%s': %s", rule.Name, w)
+	}
+	return warnings, nil
+}
+
+// lockRule locks the rule without checking its actions again. The cache is locked at once so that the
+// rule stops firing, the Kuiper rules, the interval actions and the device are updated in the background
+func lockRule(rule models.Rule) {
+	if rule.AdminState == ctModels.Locked {
+		return
+	}
+	oldRule := rule
+	rule.AdminState = ctModels.Locked
+
+	cache.Rules().Update(rule)
+	cancelRuleTimers(rule.Id, false)
+	cancelRuleRamps(rule.Id)
+	cancelRuleExecutions(rule.Id)
+
+	go func() {
+		if err := updateRuleConditions(rule, oldRule); err != nil {
+			lc.Errorf("lock rule conditions '%s' error: %s", rule.Name, err.Error())
+		}
+		if err := service.RunningService().UpdateDevice(ruleDevice(rule)); err != nil {
+			lc.Errorf("lock rule '%s' in database error: %s", rule.Name, err.Error())
+		}
+	}()
+}
+
+// ruleDevice returns the AutoScenario device which stores the rule
+func ruleDevice(rule models.Rule) ctModels.Device {
+	return ctModels.Device{
+		Id:             rule.Id,
+		Name:           rule.Name,
+		Description:    rule.Description,
+		AdminState:     rule.AdminState,
+		OperatingState: ctModels.Up,
+		Protocols:      models.RuleToProperties(rule),
+		ProfileName:    cm.AutoScenarioProfile,
+		ServiceName:    cm.DeviceServiceName,
+	}
 }
 
 func DeleteRuleByName(name string) errors.EdgeX {
@@ -301,6 +428,11 @@ func DeleteRuleByName(name string) errors.EdgeX {
 	}
 
 	cache.Rules().RemoveByName(name)
+	ruleWrites.delete(rule.Id)
+	cancelRuleTimers(rule.Id, false)
+	cancelRuleRamps(rule.Id)
+	cancelRuleExecutions(rule.Id)
+	storms.reset(rule.Id)
 	lc.Debugf("delete rule '%s' success", rule.Name)
 	return nil
 }
@@ -324,16 +456,33 @@ func TriggerRuleById(id string, contentTrigger models.ContentTrigger) {
 	}
 
 	newState := *contentTrigger.TriggerState
-	cache.Rules().UpdateStateRule(id, index, newState)
+	source := models.KuiperStateSource
+	if rule.Conditions[index].Type == cm.ScheduleRuleType {
+		source = models.SchedulerStateSource
+	}
+	cache.Rules().UpdateStateRule(id, index, newState, source)
 	defer func() {
 		if rule.Conditions[index].Type == cm.ScheduleRuleType {
-			cache.Rules().UpdateStateRule(id, index, false)
+			cache.Rules().UpdateStateRule(id, index, false, models.ResetStateSource)
 		}
 	}()
 
 	if checkRuleConditions(id) {
+		if IsPaused(rule.Name) {
+			lc.Debugf("automations paused -> rule '%s' no excute actions", rule.Name)
+			return
+		}
+		if fires, storm := storms.record(id); storm {
+			breakStorm(rule, fires)
+			return
+		}
 		lc.Infof("rule '%s' triggered", rule.Name)
-		triggerRule(rule.Name)
+		cache.Rules().UpdateFiredRule(id)
+		triggerRule(rule.Name, contentTrigger)
+	} else {
+		// the rule is cleared
+		cancelRuleTimers(id, true)
+		cancelRuleRamps(id)
 	}
 }
 
@@ -342,7 +491,6 @@ func generateName(baseName string, index int) string {
 }
 
 func _addIntervalScheduleAdd(rule models.Rule, index int) error {
-	ctx := context.Background()
 	name := generateName(rule.Id, index)
 
 	interval := dtos.Interval{
@@ -358,13 +506,12 @@ func _addIntervalScheduleAdd(rule models.Rule, index int) error {
 		},
 		Interval: interval,
 	}
-	_, err := intervalClient.Add(ctx, reqs)
-
-	return err
+	return requestWithTimeout(func(ctx context.Context) error {
+		return schedulerClient.AddIntervals(ctx, reqs)
+	})
 }
 
 func _updateIntervalSchedule(rule models.Rule, index int) error {
-	ctx := context.Background()
 	name := generateName(rule.Id, index)
 
 	interval := dtos.UpdateInterval{
@@ -380,14 +527,12 @@ func _updateIntervalSchedule(rule models.Rule, index int) error {
 		},
 		Interval: interval,
 	}
-	_, err := intervalClient.Update(ctx, reqs)
-
-	return err
+	return requestWithTimeout(func(ctx context.Context) error {
+		return schedulerClient.UpdateIntervals(ctx, reqs)
+	})
 }
 
 func _addIntervalActionSchedule(rule models.Rule, index int) error {
-	ctx := context.Background()
-
 	name := generateName(rule.Id, index)
 	action := dtos.IntervalAction{
 		Name:         name,
@@ -412,14 +557,12 @@ func _addIntervalActionSchedule(rule models.Rule, index int) error {
 		},
 		Action: action,
 	}
-	intervalActionClient.Add(ctx, reqs)
-
-	return nil
+	return requestWithTimeout(func(ctx context.Context) error {
+		return schedulerClient.AddIntervalActions(ctx, reqs)
+	})
 }
 
 func _updateIntervalActionSchedule(rule models.Rule, index int) error {
-	ctx := context.Background()
-
 	name := generateName(rule.Id, index)
 	content := fmt.Sprintf("{\"triggerState\":true, \"triggerIndex\":\"%d\"}", index)
 	action := dtos.UpdateIntervalAction{
@@ -444,9 +587,9 @@ func _updateIntervalActionSchedule(rule models.Rule, index int) error {
 		},
 		Action: action,
 	}
-	intervalActionClient.Update(ctx, reqs)
-
-	return nil
+	return requestWithTimeout(func(ctx context.Context) error {
+		return schedulerClient.UpdateIntervalActions(ctx, reqs)
+	})
 }
 
 func updateScheduleState(rule models.Rule, index int) error {
@@ -457,7 +600,7 @@ func _addRuleEngine(rule models.Rule, index int) error {
 	name := generateName(rule.Id, index)
 
 	c := rule.Conditions[index]
-	ruleStr := fmt.Sprintf(AddRuleSQLTemplate, name, c.ResourceThreshold, c.OperatorThreshold, c.ValueThreshold, StreamName, c.DeviceThreshold,
+	ruleStr := fmt.Sprintf(AddRuleSQLTemplate, name, c.ResourceThreshold, c.OperatorThreshold, c.ValueThreshold, c.ResourceThreshold, StreamName, c.DeviceThreshold,
 		c.ResourceThreshold, c.OperatorThreshold, c.ValueThreshold,
 		c.ResourceThreshold, c.OperatorThreshold, c.ValueThreshold,
 		host, port, rule.Id, index)
@@ -476,7 +619,7 @@ func _updateRuleEngine(rule models.Rule, index int) error {
 	name := generateName(rule.Id, index)
 
 	c := rule.Conditions[index]
-	ruleStr := fmt.Sprintf(UpdateRuleSQLTemplate, c.ResourceThreshold, c.OperatorThreshold, c.ValueThreshold, StreamName, c.DeviceThreshold,
+	ruleStr := fmt.Sprintf(UpdateRuleSQLTemplate, c.ResourceThreshold, c.OperatorThreshold, c.ValueThreshold, c.ResourceThreshold, StreamName, c.DeviceThreshold,
 		c.ResourceThreshold, c.OperatorThreshold, c.ValueThreshold,
 		c.ResourceThreshold, c.OperatorThreshold, c.ValueThreshold,
 		host, port, rule.Id, index)
@@ -572,15 +715,15 @@ func _deleteRuleElement(rule models.Rule, index int) error {
 		return err
 	}
 
-	ctx := context.Background()
-
-	if _, err := intervalActionClient.DeleteIntervalActionByName(ctx, name); err != nil {
-		return err
-	}
-	if _, err := intervalClient.DeleteIntervalByName(ctx, name); err != nil {
+	err := requestWithTimeout(func(ctx context.Context) error {
+		return schedulerClient.DeleteIntervalActionByName(ctx, name)
+	})
+	if err != nil {
 		return err
 	}
-	return nil
+	return requestWithTimeout(func(ctx context.Context) error {
+		return schedulerClient.DeleteIntervalByName(ctx, name)
+	})
 }
 
 func addRuleConditions(rule models.Rule) error {
@@ -641,75 +784,190 @@ func checkRuleConditions(id string) bool {
 	if !ok {
 		return false
 	}
-	if len(rule.Conditions) <= 0 {
-		return false
+
+	return combineConditionStates(rule, cache.Rules().GetConditionStatesRule(id)) == models.ConditionTrue
+}
+
+// combineConditionStates applies the logic of the conditions of the rule to their states, an unknown
+// state never makes an AND true, so the rule only fires if the result is true
+func combineConditionStates(rule models.Rule, states []string) string {
+	if len(rule.Conditions) <= 0 || len(states) < len(rule.Conditions) {
+		return models.ConditionFalse
 	}
 
-	result := cache.Rules().GetStateRule(id, 0)
+	result := states[0]
 	for index := 1; index < len(rule.Conditions); index++ {
-		state := cache.Rules().GetStateRule(id, index)
+		state := states[index]
 		if rule.Conditions[index].Logic == cm.AndLogic {
-			result = result && state
+			result = andStates(result, state)
 		} else {
-			result = result || state
+			result = orStates(result, state)
 		}
 	}
 
 	return result
 }
 
-func triggerRule(name string) {
+func andStates(a string, b string) string {
+	switch {
+	case a == models.ConditionFalse || b == models.ConditionFalse:
+		return models.ConditionFalse
+	case a == models.ConditionUnknown || b == models.ConditionUnknown:
+		return models.ConditionUnknown
+	default:
+		return models.ConditionTrue
+	}
+}
+
+func orStates(a string, b string) string {
+	switch {
+	case a == models.ConditionTrue || b == models.ConditionTrue:
+		return models.ConditionTrue
+	case a == models.ConditionUnknown || b == models.ConditionUnknown:
+		return models.ConditionUnknown
+	default:
+		return models.ConditionFalse
+	}
+}
+
+func triggerRule(name string, contentTrigger models.ContentTrigger) {
 	rule, ok := cache.Rules().ForName(name)
 	if !ok {
 		return
 	}
 
-	ctx := context.Background()
-	if rule.Actions != nil {
-		for index, action := range rule.Actions {
-			bodyParam, err := parseBody(action.Body)
-			if err != nil {
-				lc.Errorf("Trigger rule '%s' error: parse content of action[%d] error: %s -> Abort action[%d]", name, index, err.Error(), index)
-				continue
-			}
-			_, err = commandClient.IssueSetCommandByName(ctx, action.DeviceName, action.CommandName, bodyParam)
-			if err != nil {
-				lc.Errorf("Trigger rule '%s' error: execute action[%d] error:%s", name, index, err.Error())
-			} else {
-				lc.Debugf("Trigger rule '%s' execute action[%d] success", name, index)
-			}
+	ctx, done := startExecution(rule)
+	defer done()
+	record := newExecutionRecord(rule, models.TriggerSource, contentTrigger)
+	tc := newTriggerContext(rule, contentTrigger)
+	result, notificationErr := executeRule(ctx, rule, tc)
+	if rule.NotificationEnabled() {
+		if notificationErr != nil {
+			record.NotificationStatus = models.ActionFailed
+			record.NotificationError = notificationErr.Error()
+		} else {
+			record.NotificationStatus = models.ActionSucceeded
 		}
 	}
+	recordExecution(record, result)
+}
 
-	if enable, _ := strconv.ParseBool(rule.NotifyEnable); enable {
-		contentRequest := requests.NewAddNotificationRequest(
-			dtos.Notification{
-				Category: "trigger-event",
-				Content:  fmt.Sprintf("auto-scenario '%s' triggered", name),
-				Sender:   "scenario-service",
-				Severity: ctModels.Normal,
-				Status:   ctModels.New,
-			},
-		)
-		_, err := notificationClient.SendNotification(ctx, []requests.AddNotificationRequest{contentRequest})
+// ExecuteRuleByName runs the actions of the rule immediately and waits for their results, the conditions
+// of the rule are not checked. The live executions are recorded in the history with the manual source
+func ExecuteRuleByName(name string, request models.ExecuteRuleRequest) (models.RuleExecution, errors.EdgeX) {
+	rule, ok := cache.Rules().ForName(name)
+	if !ok {
+		return models.RuleExecution{}, errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, fmt.Sprintf("rule '%s' does not exists", name), nil)
+	}
+	if rule.AdminState != ctModels.Unlocked && !request.IgnoreAdminState {
+		return models.RuleExecution{}, errors.NewCommonEdgeX(errors.KindStatusConflict, fmt.Sprintf("rule '%s' is locked", name), nil)
+	}
+	if IsPaused(name) && !request.DryRun {
+		return models.RuleExecution{}, errors.NewCommonEdgeX(errors.KindStatusConflict, fmt.Sprintf("automations are paused, rule '%s' not executed", name), nil)
+	}
+
+	// no condition triggers a manual execution
+	triggerIndex := -1
+	triggerState := true
+	contentTrigger := models.ContentTrigger{TriggerIndex: &triggerIndex, TriggerState: &triggerState}
+	tc := newTriggerContext(rule, contentTrigger)
+	execution := models.RuleExecution{DryRun: request.DryRun}
+
+	var ctx context.Context
+	var done func()
+	var executor *dryRunExecutor
+	if request.DryRun {
+		executor = newDryRunExecutor(request.Readings)
+		tc.executor = executor
+		ctx, done = context.WithTimeout(context.Background(), durationOrDefault(rule.Timeout, ruleTimeout))
+	} else {
+		// a live execution is serialized with the callbacks of the rule
+		release, err := acquireRule(rule.Id, durationOrDefault(rule.Timeout, ruleTimeout))
 		if err != nil {
-			lc.Errorf("Trigger rule '%s' error: send notification error:%s", name, err.Error())
+			return models.RuleExecution{}, err
+		}
+		defer release()
+		ctx, done = startExecution(rule)
+	}
+	defer done()
+
+	lc.Infof("rule '%s' executed manually", rule.Name)
+	record := newExecutionRecord(rule, models.ManualSource, contentTrigger)
+	result, notificationErr := executeRule(ctx, rule, tc)
+	execution.Result = result
+	if rule.NotificationEnabled() {
+		if notificationErr != nil {
+			execution.NotificationStatus = models.ActionFailed
+			execution.NotificationError = notificationErr.Error()
 		} else {
-			lc.Debugf("Trigger rule '%s' send successful notification", name)
+			execution.NotificationStatus = models.ActionSucceeded
 		}
 	}
-}
 
-func parseBody(params string) (paramMap map[string]string, err error) {
-	err = json.Unmarshal([]byte(params), &paramMap)
-	if err != nil {
-		return
+	if executor != nil {
+		execution.Effects = executor.effects
+	} else {
+		record.NotificationStatus = execution.NotificationStatus
+		record.NotificationError = execution.NotificationError
+		recordExecution(record, result)
 	}
+	return execution, nil
+}
 
-	if len(paramMap) == 0 {
-		err = fmt.Errorf("no parameters specified")
-		return
+// executeRule runs the actions of the rule then sends its notification if it is enabled,
+// the effects go through the executor of tc. It returns the error of the notification
+func executeRule(ctx context.Context, rule models.Rule, tc triggerContext) (models.ExecutionResult, error) {
+	name := rule.Name
+	if tc.executor.simulated() {
+		name += " (dry run)"
+	}
+	result := models.NewExecutionResult(rule)
+	stoppedBy := -1
+	for index, action := range rule.Actions {
+		if stoppedBy >= 0 {
+			result.AddAction(models.ActionResult{
+				Index:  index,
+				Type:   action.ActionType(),
+				Status: models.ActionSkipped,
+				Error:  fmt.Sprintf("sequence stopped by action[%d]", stoppedBy),
+			})
+			continue
+		}
+		if action.Delay != "" {
+			actionResult := models.ActionResult{Index: index, Type: action.ActionType(), Status: models.ActionScheduled}
+			if err := tc.executor.scheduleAction(rule, index, action, tc); err != nil {
+				actionResult.Status = models.ActionFailed
+				actionResult.Error = err.Error()
+				lc.Errorf("Trigger rule '%s' error: schedule %s action[%d] error:%s", name, action.ActionType(), index, err.Error())
+			} else {
+				lc.Debugf("Trigger rule '%s' schedule %s action[%d] after %s", name, action.ActionType(), index, action.Delay)
+			}
+			result.AddAction(actionResult)
+			continue
+		}
+
+		actionResult := executeAction(ctx, index, action, tc)
+		if actionResult.Status == models.ActionFailed {
+			lc.Errorf("Trigger rule '%s' error: execute %s action[%d] error:%s", name, actionResult.Type, index, actionResult.Error)
+		} else {
+			lc.Debugf("Trigger rule '%s' execute %s action[%d] %s", name, actionResult.Type, index, actionResult.Status)
+		}
+		result.AddAction(actionResult)
+		if stopsSequence(actionResult) {
+			stoppedBy = index
+		}
 	}
+	lc.Infof("Trigger rule '%s' actions: %d succeeded, %d failed, %d skipped, %d scheduled", name, result.Succeeded, result.Failed, result.Skipped, result.Scheduled)
 
-	return
+	var notificationErr error
+	if rule.NotificationEnabled() {
+		tc.Failures = result.Failed
+		notificationErr = sendNotification(rule, tc)
+		if notificationErr != nil {
+			lc.Errorf("Trigger rule '%s' error: send notification error:%s", name, notificationErr.Error())
+		} else {
+			lc.Debugf("Trigger rule '%s' send successful notification", name)
+		}
+	}
+	return result, notificationErr
 }
@@ -0,0 +1,49 @@
+package application
+
+import (
+	"testing"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+func TestCombineConditionStates(t *testing.T) {
+	const (
+		T = models.ConditionTrue
+		F = models.ConditionFalse
+		U = models.ConditionUnknown
+	)
+	and := models.Condition{Logic: cm.AndLogic}
+	or := models.Condition{Logic: cm.OrLogic}
+
+	tests := []struct {
+		name       string
+		conditions []models.Condition
+		states     []string
+		want       string
+	}{
+		{"no condition", nil, nil, F},
+		{"missing states", []models.Condition{and, and}, []string{T}, F},
+		{"single unknown", []models.Condition{and}, []string{U}, U},
+		{"true and unknown", []models.Condition{and, and}, []string{T, U}, U},
+		{"false and unknown", []models.Condition{and, and}, []string{U, F}, F},
+		{"true or unknown", []models.Condition{and, or}, []string{U, T}, T},
+		{"false or unknown", []models.Condition{and, or}, []string{F, U}, U},
+		{"false or false", []models.Condition{and, or}, []string{F, F}, F},
+		// the conditions are combined from the first: (T or F) and U
+		{"left to right", []models.Condition{and, or, and}, []string{T, F, U}, U},
+		// (F and U) or T
+		{"or after a false and", []models.Condition{and, and, or}, []string{F, U, T}, T},
+		// the logic of the first condition is ignored
+		{"first logic ignored", []models.Condition{or, and}, []string{T, T}, T},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := models.Rule{Conditions: tt.conditions}
+			if got := combineConditionStates(rule, tt.states); got != tt.want {
+				t.Errorf("combineConditionStates(%v) = %s, want %s", tt.states, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/edgexfoundry/device-sdk-go/v2/pkg/service"
+
+	"github.com/rddigital/device-scenario/internal/cache"
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+func executeScenarioAction(ctx context.Context, scenario *models.ScenarioAction, tc triggerContext) error {
+	if scenario == nil {
+		return fmt.Errorf("no scenario specified")
+	}
+	if tc.depth >= cm.MaxScenarioDepth {
+		return fmt.Errorf("scenario '%s' exceeds the maximum depth %d", scenario.Name, cm.MaxScenarioDepth)
+	}
+
+	actions, err := scenarioActions(scenario.Name)
+	if err != nil {
+		return err
+	}
+
+	tc.depth++
+	arrError := make([]string, 0)
+	for index, action := range actions {
+		result := executeAction(ctx, index, action, tc)
+		if result.Status == models.ActionFailed {
+			arrError = append(arrError, fmt.Sprintf("action[%d]: %s", index, result.Error))
+		}
+		if stopsSequence(result) {
+			break
+		}
+	}
+
+	if len(arrError) > 0 {
+		return fmt.Errorf("scenario '%s' some actions errored: %s", scenario.Name, strings.Join(arrError, "; "))
+	}
+	return nil
+}
+
+// scenarioActions returns the actions of the rule or the ManualScenario device with the name
+func scenarioActions(name string) ([]models.Action, error) {
+	if rule, ok := cache.Rules().ForName(name); ok {
+		return rule.Actions, nil
+	}
+
+	ds := service.RunningService()
+	device, err := ds.GetDeviceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("scenario '%s' does not exists", name)
+	}
+	if device.ProfileName != cm.ManualScenarioProfile {
+		return nil, fmt.Errorf("device '%s' is not a scenario", name)
+	}
+
+	return models.ActionsFromProperties(device.Protocols[cm.ContentsProperty]), nil
+}
+
+// validateScenarioReferences checks that all scenarios referenced by the rule exist,
+// do not come back to the rule and do not exceed the maximum depth
+func validateScenarioReferences(rule models.Rule) error {
+	return validateScenarioActions(rule.Actions, []string{rule.Name})
+}
+
+func validateScenarioActions(actions []models.Action, path []string) error {
+	for _, action := range actions {
+		if action.If != nil {
+			if err := validateScenarioActions(action.If.Then, path); err != nil {
+				return err
+			}
+			if err := validateScenarioActions(action.If.Else, path); err != nil {
+				return err
+			}
+			continue
+		}
+		if action.Wait != nil {
+			if err := validateScenarioActions(action.Wait.OnTimeout, path); err != nil {
+				return err
+			}
+			continue
+		}
+		if action.ActionType() != models.ScenarioActionType || action.Scenario == nil {
+			continue
+		}
+
+		name := action.Scenario.Name
+		for _, p := range path {
+			if p == name {
+				return fmt.Errorf("scenario cycle detected: %s -> %s", strings.Join(path, " -> "), name)
+			}
+		}
+		if len(path) > cm.MaxScenarioDepth {
+			return fmt.Errorf("scenario '%s' exceeds the maximum depth %d: %s", name, cm.MaxScenarioDepth, strings.Join(path, " -> "))
+		}
+
+		subActions, err := scenarioActions(name)
+		if err != nil {
+			return err
+		}
+		if err = validateScenarioNesting(subActions, fmt.Sprintf("scenario '%s' action", name)); err != nil {
+			return err
+		}
+
+		subPath := append(append(make([]string, 0, len(path)+1), path...), name)
+		if err = validateScenarioActions(subActions, subPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateScenarioNesting checks that the actions of a scenario run by a rule can be run inline,
+// the delayed actions, the waits and the ramps are not supported inside a scenario
+func validateScenarioNesting(actions []models.Action, prefix string) error {
+	for i, action := range actions {
+		index := fmt.Sprintf("%s[%d]", prefix, i)
+		if action.Delay != "" {
+			return fmt.Errorf("%s delay is not supported inside a scenario", index)
+		}
+		switch action.ActionType() {
+		case models.WaitActionType, models.RampActionType:
+			return fmt.Errorf("%s %s is not supported inside a scenario", index, action.ActionType())
+		}
+		if action.If != nil {
+			if err := validateScenarioNesting(action.If.Then, index+".then"); err != nil {
+				return err
+			}
+			if err := validateScenarioNesting(action.If.Else, index+".else"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
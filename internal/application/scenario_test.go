@@ -0,0 +1,102 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+func runScenario(name string) models.Action {
+	return models.Action{Scenario: &models.ScenarioAction{Name: name}}
+}
+
+func TestValidateScenarioActionsRejectsCycles(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions []models.Action
+		path    []string
+		wantErr string
+	}{
+		{
+			name:    "rule runs itself",
+			actions: []models.Action{runScenario("night-mode")},
+			path:    []string{"night-mode"},
+			wantErr: "scenario cycle detected: night-mode -> night-mode",
+		},
+		{
+			name:    "scenario runs a scenario of its path",
+			actions: []models.Action{{DeviceName: "lamp", CommandName: "switch", Body: `{"on":"false"}`}, runScenario("leave-home")},
+			path:    []string{"leave-home", "night-mode"},
+			wantErr: "scenario cycle detected: leave-home -> night-mode -> leave-home",
+		},
+		{
+			name:    "path deeper than the maximum",
+			actions: []models.Action{runScenario("s6")},
+			path:    []string{"s0", "s1", "s2", "s3", "s4", "s5"},
+			wantErr: "scenario 's6' exceeds the maximum depth 5",
+		},
+		{
+			name:    "no scenario action",
+			actions: []models.Action{{DeviceName: "lamp", CommandName: "switch", Body: `{"on":"true"}`}},
+			path:    []string{"night-mode"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateScenarioActions(tt.actions, tt.path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecuteScenarioActionStopsAtMaximumDepth(t *testing.T) {
+	tc := triggerContext{RuleName: "night-mode", depth: cm.MaxScenarioDepth}
+	err := executeScenarioAction(context.Background(), &models.ScenarioAction{Name: "leave-home"}, tc)
+	if err == nil || !strings.Contains(err.Error(), "exceeds the maximum depth") {
+		t.Fatalf("error = %v, want the maximum depth error", err)
+	}
+}
+
+func TestValidateScenarioNesting(t *testing.T) {
+	lamp := models.Action{DeviceName: "lamp", CommandName: "switch", Body: `{"on":"true"}`}
+	delayed := lamp
+	delayed.Delay = "5m"
+
+	tests := []struct {
+		name    string
+		actions []models.Action
+		wantErr string
+	}{
+		{"inline actions", []models.Action{lamp, runScenario("leave-home")}, ""},
+		{"delayed action", []models.Action{lamp, delayed}, "scenario 'night-mode' action[1] delay is not supported inside a scenario"},
+		{"wait action", []models.Action{{Wait: &models.WaitAction{Timeout: "1m"}}}, "scenario 'night-mode' action[0] wait is not supported inside a scenario"},
+		{"ramp action", []models.Action{{Ramp: &models.RampAction{Duration: "1m"}}}, "scenario 'night-mode' action[0] ramp is not supported inside a scenario"},
+		{"delayed action in a branch", []models.Action{{If: &models.IfAction{Else: []models.Action{lamp, delayed}}}}, "scenario 'night-mode' action[0].else[1] delay is not supported inside a scenario"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateScenarioNesting(tt.actions, "scenario 'night-mode' action")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+	ctModels "github.com/edgexfoundry/go-mod-core-contracts/v2/models"
+
+	"github.com/rddigital/device-scenario/internal/cache"
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// dryRunExecutor records the effects of the actions instead of sending them. The resources are read
+// from the simulated readings, which are updated by the simulated commands
+type dryRunExecutor struct {
+	readings  map[string]string           // key is "{deviceName}/{resourceName}"
+	snapshots map[string]*models.Snapshot // nil if the snapshot is deleted by the run
+	effects   []models.SimulatedEffect
+}
+
+func newDryRunExecutor(readings []models.SimulatedReading) *dryRunExecutor {
+	executor := &dryRunExecutor{
+		readings:  make(map[string]string, len(readings)),
+		snapshots: make(map[string]*models.Snapshot),
+		effects:   make([]models.SimulatedEffect, 0),
+	}
+	for _, r := range readings {
+		executor.readings[readingKey(r.DeviceName, r.ResourceName)] = r.Value
+	}
+	return executor
+}
+
+func readingKey(deviceName string, resourceName string) string {
+	return deviceName + "/" + resourceName
+}
+
+func (d *dryRunExecutor) readResource(_ context.Context, remote string, deviceName string, resourceName string) (string, error) {
+	if _, err := commandClientFor(remote); err != nil {
+		return "", err
+	}
+	value, ok := d.readings[readingKey(deviceName, resourceName)]
+	if !ok {
+		return "", fmt.Errorf("no simulated reading of resource '%s' of device '%s'", resourceName, deviceName)
+	}
+	return value, nil
+}
+
+func (d *dryRunExecutor) setCommand(_ context.Context, remote string, deviceName string, commandName string, params map[string]string) error {
+	if _, err := commandClientFor(remote); err != nil {
+		return err
+	}
+	d.effects = append(d.effects, models.SimulatedEffect{
+		Type:        models.CommandEffect,
+		Remote:      remote,
+		DeviceName:  deviceName,
+		CommandName: commandName,
+		Parameters:  params,
+	})
+	for name, value := range params {
+		d.readings[readingKey(deviceName, name)] = value
+	}
+	return nil
+}
+
+func (d *dryRunExecutor) callWebhook(_ context.Context, webhook *models.WebhookAction, body []byte, _ time.Duration) error {
+	d.effects = append(d.effects, models.SimulatedEffect{
+		Type:    models.WebhookEffect,
+		Method:  webhook.Method,
+		Url:     webhook.Url,
+		Headers: webhook.Headers,
+		Body:    string(body),
+	})
+	return nil
+}
+
+func (d *dryRunExecutor) publish(_ context.Context, topic string, payload []byte, qos int, retained bool) error {
+	if messageBusClient == nil {
+		return fmt.Errorf("message bus is not configured")
+	}
+	d.effects = append(d.effects, models.SimulatedEffect{
+		Type:     models.PublishEffect,
+		Topic:    topic,
+		Payload:  string(payload),
+		Qos:      qos,
+		Retained: retained,
+	})
+	return nil
+}
+
+func (d *dryRunExecutor) startRamp(_ string, ramp *models.RampAction, _ string, start float64, _ time.Duration, _ time.Duration) {
+	d.effects = append(d.effects, models.SimulatedEffect{
+		Type:        models.RampEffect,
+		Remote:      ramp.Remote,
+		DeviceName:  ramp.DeviceName,
+		CommandName: ramp.CommandName,
+		Ramp:        ramp,
+		Start:       &start,
+	})
+}
+
+func (d *dryRunExecutor) saveSnapshot(snapshot models.Snapshot) error {
+	d.snapshots[snapshot.Name] = &snapshot
+	d.effects = append(d.effects, models.SimulatedEffect{
+		Type:     models.SnapshotEffect,
+		Snapshot: &snapshot,
+	})
+	return nil
+}
+
+// loadSnapshot returns the snapshot taken by the run, or the saved snapshot if the run did not change it
+func (d *dryRunExecutor) loadSnapshot(name string) (models.Snapshot, bool) {
+	if snapshot, ok := d.snapshots[name]; ok {
+		if snapshot == nil {
+			return models.Snapshot{}, false
+		}
+		return *snapshot, true
+	}
+	return loadSnapshot(name)
+}
+
+func (d *dryRunExecutor) deleteSnapshot(name string) error {
+	snapshot, ok := d.loadSnapshot(name)
+	if !ok {
+		return fmt.Errorf("snapshot '%s' does not exists", name)
+	}
+	d.snapshots[name] = nil
+	d.effects = append(d.effects, models.SimulatedEffect{
+		Type:     models.DeleteSnapshotEffect,
+		Snapshot: &snapshot,
+	})
+	return nil
+}
+
+func (d *dryRunExecutor) scheduleAction(_ models.Rule, index int, action models.Action, _ triggerContext) error {
+	d.effects = append(d.effects, models.SimulatedEffect{
+		Type:        models.ScheduleEffect,
+		ActionIndex: &index,
+		Action:      &action,
+	})
+	return nil
+}
+
+func (d *dryRunExecutor) sendNotification(notification dtos.Notification) error {
+	d.effects = append(d.effects, models.SimulatedEffect{
+		Type:         models.NotificationEffect,
+		Notification: &notification,
+	})
+	return nil
+}
+
+func (d *dryRunExecutor) simulated() bool {
+	return true
+}
+
+// SimulateRule evaluates the conditions of the rule with the hypothetical states or readings of the
+// request, then runs its actions with the dry-run executor. Nothing is sent to core-command,
+// support-notifications or the other services of the actions
+func SimulateRule(request models.SimulationRequest) (models.SimulationResult, errors.EdgeX) {
+	rule, edgexErr := simulatedRule(request)
+	if edgexErr != nil {
+		return models.SimulationResult{}, edgexErr
+	}
+
+	executor := newDryRunExecutor(request.Readings)
+	states, triggerValue, err := simulatedStates(rule, request, executor.readings)
+	if err != nil {
+		return models.SimulationResult{}, errors.NewCommonEdgeX(errors.KindContractInvalid, fmt.Sprintf("simulate rule '%s' error: %s", rule.Name, err.Error()), nil)
+	}
+
+	simulation := models.SimulationResult{
+		RuleName:        rule.Name,
+		AdminState:      string(rule.AdminState),
+		ConditionStates: states,
+		TriggerIndex:    request.TriggerIndex,
+		Result:          combineConditionStates(rule, states),
+	}
+	simulation.Fired = simulation.Result == models.ConditionTrue && rule.AdminState == ctModels.Unlocked
+
+	if simulation.Result == models.ConditionTrue {
+		triggerState := states[request.TriggerIndex] == models.ConditionTrue
+		contentTrigger := models.ContentTrigger{
+			TriggerIndex: &request.TriggerIndex,
+			TriggerState: &triggerState,
+			TriggerValue: triggerValue,
+		}
+		tc := newTriggerContext(rule, contentTrigger)
+		tc.executor = executor
+
+		ctx, cancel := context.WithTimeout(context.Background(), durationOrDefault(rule.Timeout, ruleTimeout))
+		defer cancel()
+		result, notificationErr := executeRule(ctx, rule, tc)
+		simulation.Execution = &result
+		if notificationErr != nil {
+			simulation.NotificationError = notificationErr.Error()
+		}
+	}
+	simulation.Effects = executor.effects
+
+	return simulation, nil
+}
+
+// simulatedRule returns the rule of the request, a new rule is validated like a rule which is added
+func simulatedRule(request models.SimulationRequest) (models.Rule, errors.EdgeX) {
+	name := request.RuleName
+	if name == "" && request.Rule != nil {
+		name = request.Rule.Name
+	}
+	existing, exists := cache.Rules().ForName(name)
+
+	if request.Rule == nil {
+		if !exists {
+			return models.Rule{}, errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, fmt.Sprintf("rule '%s' does not exists", name), nil)
+		}
+		return existing, nil
+	}
+
+	rule := *request.Rule
+	if exists {
+		// the states of the conditions of the existing rule are kept
+		rule.Id = existing.Id
+		rule.Name = existing.Name
+		if rule.AdminState == "" {
+			rule.AdminState = existing.AdminState
+		}
+	} else {
+		rule.Id = ""
+		rule.AdminState = ctModels.Unlocked
+	}
+
+	if len(rule.Conditions) == 0 {
+		return models.Rule{}, errors.NewCommonEdgeX(errors.KindContractInvalid, "rule conditions empty", nil)
+	}
+	for index, condition := range rule.Conditions {
+		if err := cm.Validate(condition); err != nil {
+			return models.Rule{}, errors.NewCommonEdgeX(errors.KindContractInvalid, fmt.Sprintf("condition[%d] invalid", index), err)
+		}
+	}
+	if err := validateActions(rule, nil); err != nil {
+		return models.Rule{}, errors.NewCommonEdgeX(errors.KindContractInvalid, fmt.Sprintf("simulate rule '%s' error: %s", rule.Name, err.Error()), nil)
+	}
+	return rule, nil
+}
+
+// simulatedStates returns the states of the conditions and the value of the trigger, see models.SimulationRequest
+func simulatedStates(rule models.Rule, request models.SimulationRequest, readings map[string]string) ([]string, interface{}, error) {
+	if request.TriggerIndex >= len(rule.Conditions) {
+		return nil, nil, fmt.Errorf("rule do not have the %d-rd condition", request.TriggerIndex)
+	}
+	if len(request.ConditionStates) > 0 && len(request.ConditionStates) != len(rule.Conditions) {
+		return nil, nil, fmt.Errorf("%d condition states for %d conditions", len(request.ConditionStates), len(rule.Conditions))
+	}
+
+	current := cache.Rules().GetConditionStatesRule(rule.Id)
+	states := make([]string, len(rule.Conditions))
+	for index := range states {
+		states[index] = models.ConditionFalse
+		if index < len(request.ConditionStates) {
+			states[index] = models.ConditionStateOf(request.ConditionStates[index])
+		}
+	}
+
+	var triggerValue interface{}
+	for index, c := range rule.Conditions {
+		var value string
+		var hasReading bool
+		if c.Type == cm.ThresholdRuleType {
+			value, hasReading = readings[readingKey(c.DeviceThreshold, c.ResourceThreshold)]
+		}
+		if index == request.TriggerIndex && hasReading {
+			triggerValue = value
+		}
+		if len(request.ConditionStates) > 0 {
+			continue
+		}
+
+		switch {
+		case c.Type == cm.ScheduleRuleType:
+			// a schedule condition is reset after each callback
+			states[index] = models.ConditionStateOf(index == request.TriggerIndex)
+		case hasReading:
+			ok, err := compareValues(value, c.OperatorThreshold, c.ValueThreshold)
+			if err != nil {
+				return nil, nil, fmt.Errorf("condition[%d]: %s", index, err.Error())
+			}
+			states[index] = models.ConditionStateOf(ok)
+		case index < len(current):
+			states[index] = current[index]
+		}
+	}
+
+	return states, triggerValue, nil
+}
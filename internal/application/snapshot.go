@@ -0,0 +1,179 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+	"github.com/rddigital/device-scenario/internal/store"
+)
+
+type snapshotManager struct {
+	snapshots map[string]models.Snapshot // key is snapshot name
+	mutex     sync.RWMutex
+}
+
+var (
+	snapshots = &snapshotManager{
+		snapshots: make(map[string]models.Snapshot),
+	}
+)
+
+// initSnapshots restores the snapshots saved before the restart
+func initSnapshots() {
+	var arrSnapshot []models.Snapshot
+	if _, err := store.Local().Load(cm.SnapshotsStoreName, &arrSnapshot); err != nil {
+		lc.Errorf("load snapshots error: %s", err.Error())
+		return
+	}
+
+	snapshots.mutex.Lock()
+	defer snapshots.mutex.Unlock()
+
+	for _, s := range arrSnapshot {
+		snapshots.snapshots[s.Name] = s
+	}
+}
+
+// executeSnapshotAction reads all resources before saving, so a failed read
+// never replaces a complete snapshot with a partial one
+func executeSnapshotAction(ctx context.Context, snapshot *models.SnapshotAction, tc triggerContext) error {
+	if snapshot == nil {
+		return fmt.Errorf("no snapshot specified")
+	}
+
+	values := make([]models.SnapshotValue, 0, len(snapshot.Resources))
+	for _, resource := range snapshot.Resources {
+		value, err := tc.executor.readResource(ctx, resource.Remote, resource.DeviceName, resource.ResourceName)
+		if err != nil {
+			return fmt.Errorf("snapshot '%s' error: %s", snapshot.Name, err.Error())
+		}
+		values = append(values, models.SnapshotValue{
+			SnapshotResource: resource,
+			Value:            value,
+		})
+	}
+
+	return tc.executor.saveSnapshot(models.Snapshot{
+		Name:     snapshot.Name,
+		RuleName: tc.RuleName,
+		Created:  time.Now().UnixNano(),
+		Values:   values,
+	})
+}
+
+func executeRestoreAction(ctx context.Context, restore *models.RestoreAction, tc triggerContext) error {
+	if restore == nil {
+		return fmt.Errorf("no restore specified")
+	}
+
+	snapshot, ok := tc.executor.loadSnapshot(restore.Name)
+	if !ok {
+		return fmt.Errorf("snapshot '%s' does not exists", restore.Name)
+	}
+
+	arrError := make([]string, 0)
+	arrOverridden := make([]string, 0)
+	for _, v := range snapshot.Values {
+		commandName := v.CommandName
+		if commandName == "" {
+			commandName = v.ResourceName
+		}
+		bodyParam := map[string]string{v.ResourceName: v.Value}
+		write := commandWrite{remote: v.Remote, deviceName: v.DeviceName, commandName: commandName, params: bodyParam}
+		if other, ok := arbitrate(tc.RuleId, tc.priority, write); ok {
+			arrOverridden = append(arrOverridden, fmt.Sprintf("%s/%s by rule '%s'", v.DeviceName, commandName, other.Name))
+			continue
+		}
+		if err := tc.executor.setCommand(ctx, v.Remote, v.DeviceName, commandName, bodyParam); err != nil {
+			arrError = append(arrError, fmt.Sprintf("%s/%s: %s", v.DeviceName, commandName, err.Error()))
+		}
+	}
+	if len(arrError) > 0 {
+		return fmt.Errorf("restore snapshot '%s' some resources errored: %s", restore.Name, strings.Join(arrError, "; "))
+	}
+	if len(arrOverridden) > 0 {
+		if len(arrOverridden) == len(snapshot.Values) {
+			return fmt.Errorf("%w: overridden by active rules: %s", errActionSkipped, strings.Join(arrOverridden, "; "))
+		}
+		lc.Infof("restore snapshot '%s' of rule '%s' overridden by active rules: %s", restore.Name, tc.RuleName, strings.Join(arrOverridden, "; "))
+	}
+
+	if restore.Delete {
+		return tc.executor.deleteSnapshot(restore.Name)
+	}
+	return nil
+}
+
+// saveSnapshot replaces the snapshot with the same name and saves all snapshots
+func saveSnapshot(snapshot models.Snapshot) error {
+	snapshots.mutex.Lock()
+	defer snapshots.mutex.Unlock()
+
+	snapshots.snapshots[snapshot.Name] = snapshot
+	return snapshots.save()
+}
+
+func loadSnapshot(name string) (models.Snapshot, bool) {
+	snapshots.mutex.RLock()
+	defer snapshots.mutex.RUnlock()
+
+	snapshot, ok := snapshots.snapshots[name]
+	return snapshot, ok
+}
+
+func GetAllSnapshots() []models.Snapshot {
+	snapshots.mutex.RLock()
+	defer snapshots.mutex.RUnlock()
+
+	arrSnapshot := make([]models.Snapshot, 0, len(snapshots.snapshots))
+	for _, s := range snapshots.snapshots {
+		arrSnapshot = append(arrSnapshot, s)
+	}
+	sort.Slice(arrSnapshot, func(i, j int) bool {
+		return arrSnapshot[i].Name < arrSnapshot[j].Name
+	})
+
+	return arrSnapshot
+}
+
+func GetSnapshotByName(name string) (models.Snapshot, errors.EdgeX) {
+	snapshots.mutex.RLock()
+	defer snapshots.mutex.RUnlock()
+
+	snapshot, ok := snapshots.snapshots[name]
+	if !ok {
+		return models.Snapshot{}, errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, fmt.Sprintf("snapshot '%s' does not exists", name), nil)
+	}
+	return snapshot, nil
+}
+
+func DeleteSnapshotByName(name string) errors.EdgeX {
+	snapshots.mutex.Lock()
+	defer snapshots.mutex.Unlock()
+
+	if _, ok := snapshots.snapshots[name]; !ok {
+		return errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, fmt.Sprintf("snapshot '%s' does not exists", name), nil)
+	}
+	delete(snapshots.snapshots, name)
+	if err := snapshots.save(); err != nil {
+		return errors.NewCommonEdgeX(errors.KindServerError, "failed to save snapshots", err)
+	}
+	return nil
+}
+
+// save must be called with the lock held
+func (sm *snapshotManager) save() error {
+	arrSnapshot := make([]models.Snapshot, 0, len(sm.snapshots))
+	for _, s := range sm.snapshots {
+		arrSnapshot = append(arrSnapshot, s)
+	}
+	return store.Local().Save(cm.SnapshotsStoreName, arrSnapshot)
+}
@@ -0,0 +1,87 @@
+package application
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	ctModels "github.com/edgexfoundry/go-mod-core-contracts/v2/models"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// stormBreaker counts the firings of the rules in the last minute
+type stormBreaker struct {
+	fires    map[string][]time.Time // key is rule id
+	maxFires int
+	mutex    sync.Mutex
+}
+
+var (
+	storms = &stormBreaker{
+		fires:    make(map[string][]time.Time),
+		maxFires: cm.DefaultStormMaxFiresPerMinute,
+	}
+)
+
+func initStormBreaker(maxFires int) {
+	storms.mutex.Lock()
+	defer storms.mutex.Unlock()
+	storms.fires = make(map[string][]time.Time)
+	storms.maxFires = maxFires
+}
+
+// record counts a firing of the rule and returns the number of firings in the last minute,
+// and true if it is more than the maximum
+func (sb *stormBreaker) record(id string) (int, bool) {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-time.Minute)
+	fires := sb.fires[id]
+	kept := fires[:0]
+	for _, t := range fires {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	kept = append(kept, now)
+	sb.fires[id] = kept
+	return len(kept), len(kept) > sb.maxFires
+}
+
+func (sb *stormBreaker) reset(id string) {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	delete(sb.fires, id)
+}
+
+// breakStorm locks the rule which fired too often and sends a notification, it does not wait
+// for the other services so that the trigger worker is released at once
+func breakStorm(rule models.Rule, fires int) {
+	storms.reset(rule.Id)
+	lc.Warnf("rule '%s' fired %d times in the last minute -> locked", rule.Name, fires)
+
+	lockRule(rule)
+
+	var labels []string
+	if rule.Notification != nil {
+		labels = rule.Notification.Labels
+	}
+	notification := dtos.Notification{
+		Category: cm.StormNotificationCategory,
+		Labels:   labels,
+		Content:  fmt.Sprintf("auto-scenario '%s' fired %d times in the last minute and was locked", rule.Name, fires),
+		Sender:   cm.NotificationSender,
+		Severity: ctModels.Critical,
+		Status:   ctModels.New,
+	}
+	go func() {
+		if err := (liveExecutor{}).sendNotification(notification); err != nil {
+			lc.Errorf("send storm notification of rule '%s' error: %s", rule.Name, err.Error())
+		}
+	}()
+}
@@ -0,0 +1,118 @@
+package application
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/responses"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// executeTargetCommandAction sends the command action to every device matching the target,
+// the resolved devices are recorded in the result
+func executeTargetCommandAction(ctx context.Context, action models.Action, tc triggerContext, result *models.ActionResult) error {
+	devices, err := resolveTargetDevices(ctx, action.Target)
+	if err != nil {
+		return fmt.Errorf("resolve target error: %s", err.Error())
+	}
+	result.Devices = devices
+	if len(devices) == 0 {
+		lc.Debugf("no device matches the target of command '%s'", action.CommandName)
+		return errActionSkipped
+	}
+
+	skipped := 0
+	arrError := make([]string, 0)
+	for _, deviceName := range devices {
+		deviceAction := action
+		deviceAction.DeviceName = deviceName
+		deviceAction.Target = nil
+
+		err = executeCommandAction(ctx, deviceAction, tc)
+		if goErrors.Is(err, errActionSkipped) {
+			skipped++
+		} else if err != nil {
+			arrError = append(arrError, fmt.Sprintf("%s: %s", deviceName, err.Error()))
+		}
+	}
+
+	if len(arrError) > 0 {
+		return fmt.Errorf("%d/%d devices errored: %s", len(arrError), len(devices), strings.Join(arrError, "; "))
+	}
+	if skipped == len(devices) {
+		return errActionSkipped
+	}
+	return nil
+}
+
+// resolveTargetDevices returns the names of the devices in core-metadata matching the target,
+// the devices of this service are never targeted
+func resolveTargetDevices(ctx context.Context, target *models.DeviceTarget) ([]string, error) {
+	if target == nil {
+		return nil, fmt.Errorf("no target specified")
+	}
+
+	var response responses.MultiDevicesResponse
+	var err errors.EdgeX
+	if target.ProfileName != "" {
+		response, err = metadataClient.DevicesByProfileName(ctx, target.ProfileName, 0, cm.DefaultLimit)
+	} else {
+		response, err = metadataClient.AllDevices(ctx, target.Labels, 0, cm.DefaultLimit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]string, 0, len(response.Devices))
+	for _, d := range response.Devices {
+		if d.ServiceName == cm.DeviceServiceName {
+			continue
+		}
+		ok, err := matchTarget(d, target)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			devices = append(devices, d.Name)
+		}
+	}
+	sort.Strings(devices)
+
+	return devices, nil
+}
+
+func matchTarget(d dtos.Device, target *models.DeviceTarget) (bool, error) {
+	if target.ProfileName != "" && d.ProfileName != target.ProfileName {
+		return false, nil
+	}
+	if target.NamePattern != "" {
+		ok, err := path.Match(target.NamePattern, d.Name)
+		if err != nil {
+			return false, fmt.Errorf("invalid name pattern '%s': %s", target.NamePattern, err.Error())
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	for _, label := range target.Labels {
+		found := false
+		for _, l := range d.Labels {
+			if l == label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
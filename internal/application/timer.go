@@ -0,0 +1,234 @@
+package application
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+
+	"github.com/rddigital/device-scenario/internal/cache"
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+	"github.com/rddigital/device-scenario/internal/store"
+)
+
+type pendingTimer struct {
+	action models.PendingAction
+	timer  *time.Timer
+}
+
+type timerManager struct {
+	pending map[string]*pendingTimer // key is pending action id
+	persist *store.DebouncedSave
+	mutex   sync.Mutex
+}
+
+var (
+	timers = &timerManager{
+		pending: make(map[string]*pendingTimer),
+	}
+)
+
+// initTimers restores the pending actions saved before the restart,
+// the actions which should have been fired during the downtime are fired at once
+func initTimers() {
+	var actions []models.PendingAction
+	if _, err := store.Local().Load(cm.TimersStoreName, &actions); err != nil {
+		lc.Errorf("load pending actions error: %s", err.Error())
+		return
+	}
+
+	timers.mutex.Lock()
+	defer timers.mutex.Unlock()
+
+	timers.persist = store.NewDebouncedSave(cm.TimersStoreName, cm.DefaultPersistDelay, timers.snapshot, func(err error) {
+		lc.Errorf("save pending actions error: %s", err.Error())
+	})
+	for _, action := range actions {
+		if !cache.Rules().CheckExistsById(action.RuleId) {
+			continue
+		}
+		timers.start(action)
+	}
+	timers.save()
+}
+
+func scheduleAction(rule models.Rule, index int, action models.Action, tc triggerContext) error {
+	delay, err := time.ParseDuration(action.Delay)
+	if err != nil {
+		return fmt.Errorf("parse delay '%s' error: %s", action.Delay, err.Error())
+	}
+
+	now := time.Now()
+	pendingAction := models.PendingAction{
+		Id:           generateName(rule.Id, index),
+		RuleId:       rule.Id,
+		RuleName:     rule.Name,
+		ActionIndex:  index,
+		Action:       action,
+		TriggerIndex: tc.TriggerIndex,
+		TriggerState: tc.TriggerState,
+		TriggerValue: tc.Value,
+		DeviceName:   tc.DeviceName,
+		ResourceName: tc.ResourceName,
+		Created:      now.UnixNano(),
+		FireAt:       now.Add(delay).UnixNano(),
+	}
+
+	timers.mutex.Lock()
+	defer timers.mutex.Unlock()
+
+	// Restart the timer if the action is already pending
+	if pt, ok := timers.pending[pendingAction.Id]; ok {
+		pt.timer.Stop()
+		delete(timers.pending, pendingAction.Id)
+	}
+	timers.start(pendingAction)
+	timers.save()
+
+	return nil
+}
+
+// cancelRuleTimers cancels the pending actions of the rule, only the actions with CancelOnClear
+// are cancelled if onlyOnClear is set
+func cancelRuleTimers(ruleId string, onlyOnClear bool) {
+	timers.mutex.Lock()
+	defer timers.mutex.Unlock()
+
+	changed := false
+	for id, pt := range timers.pending {
+		if pt.action.RuleId != ruleId {
+			continue
+		}
+		if onlyOnClear && !pt.action.Action.CancelOnClear {
+			continue
+		}
+		pt.timer.Stop()
+		delete(timers.pending, id)
+		changed = true
+		lc.Debugf("pending action '%s' of rule '%s' cancelled", id, pt.action.RuleName)
+	}
+
+	if changed {
+		timers.save()
+	}
+}
+
+func GetAllPendingActions() []models.PendingAction {
+	timers.mutex.Lock()
+	defer timers.mutex.Unlock()
+
+	actions := make([]models.PendingAction, 0, len(timers.pending))
+	for _, pt := range timers.pending {
+		actions = append(actions, pt.action)
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].FireAt < actions[j].FireAt
+	})
+
+	return actions
+}
+
+func CancelPendingActionById(id string) errors.EdgeX {
+	timers.mutex.Lock()
+	defer timers.mutex.Unlock()
+
+	pt, ok := timers.pending[id]
+	if !ok {
+		return errors.NewCommonEdgeX(errors.KindEntityDoesNotExist, fmt.Sprintf("pending action '%s' does not exists", id), nil)
+	}
+	pt.timer.Stop()
+	delete(timers.pending, id)
+	timers.save()
+
+	return nil
+}
+
+// start must be called with the lock held
+func (tm *timerManager) start(action models.PendingAction) {
+	delay := time.Until(time.Unix(0, action.FireAt))
+	pt := &pendingTimer{
+		action: action,
+	}
+	pt.timer = time.AfterFunc(delay, func() {
+		tm.fire(pt)
+	})
+	tm.pending[action.Id] = pt
+}
+
+func (tm *timerManager) fire(pt *pendingTimer) {
+	tm.mutex.Lock()
+	// the timer may be restarted or cancelled while waiting for the lock
+	if current, ok := tm.pending[pt.action.Id]; !ok || current != pt {
+		tm.mutex.Unlock()
+		return
+	}
+	delete(tm.pending, pt.action.Id)
+	tm.save()
+	tm.mutex.Unlock()
+
+	// the delayed action is run one at a time with the callbacks of the rule
+	action := pt.action
+	if err := enqueue(action.RuleId, func() { runPendingAction(action) }); err != nil {
+		lc.Errorf("pending action '%s' of rule '%s' dropped: %s", action.Id, action.RuleName, err.Error())
+	}
+}
+
+func runPendingAction(action models.PendingAction) {
+	rule, ok := cache.Rules().ForId(action.RuleId)
+	if !ok {
+		lc.Errorf("rule with id '%s' of pending action '%s' does not exists", action.RuleId, action.Id)
+		return
+	}
+	if IsPaused(rule.Name) {
+		lc.Infof("automations paused -> pending action '%s' of rule '%s' dropped", action.Id, rule.Name)
+		return
+	}
+
+	ctx, done := startExecution(rule)
+	defer done()
+	contentTrigger := models.ContentTrigger{TriggerIndex: &action.TriggerIndex, TriggerState: &action.TriggerState, TriggerValue: action.TriggerValue}
+	record := newExecutionRecord(rule, models.TimerSource, contentTrigger)
+	tc := newTriggerContext(rule, contentTrigger)
+	// the condition may have been changed since the action was scheduled
+	tc.DeviceName = action.DeviceName
+	tc.ResourceName = action.ResourceName
+	result := executeAction(ctx, action.ActionIndex, action.Action, tc)
+	if result.Status == models.ActionFailed {
+		lc.Errorf("Trigger rule '%s' error: execute delayed %s action[%d] error:%s", rule.Name, result.Type, result.Index, result.Error)
+	} else {
+		lc.Debugf("Trigger rule '%s' execute delayed %s action[%d] %s", rule.Name, result.Type, result.Index, result.Status)
+	}
+
+	executionResult := models.NewExecutionResult(rule)
+	executionResult.AddAction(result)
+	recordExecution(record, executionResult)
+}
+
+// save schedules the save of the pending actions, it must be called with the lock held
+func (tm *timerManager) save() {
+	if tm.persist != nil {
+		tm.persist.Schedule()
+	}
+}
+
+// snapshot returns a copy of the pending actions to save
+func (tm *timerManager) snapshot() interface{} {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	actions := make([]models.PendingAction, 0, len(tm.pending))
+	for _, pt := range tm.pending {
+		actions = append(actions, pt.action)
+	}
+	return actions
+}
+
+// flushTimers saves the pending actions at once if a save is scheduled
+func flushTimers() {
+	if timers.persist != nil {
+		timers.persist.Flush()
+	}
+}
@@ -0,0 +1,144 @@
+package application
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+	"github.com/rddigital/device-scenario/internal/store"
+)
+
+func savedPendingActions(t *testing.T) []models.PendingAction {
+	t.Helper()
+	flushTimers()
+	var actions []models.PendingAction
+	if _, err := store.Local().Load(cm.TimersStoreName, &actions); err != nil {
+		t.Fatalf("load pending actions error: %v", err)
+	}
+	return actions
+}
+
+func TestScheduleActionRestartsThePendingTimer(t *testing.T) {
+	rule := models.Rule{Id: "5b0f0d0e-0f7c-4c2f-9b9e-1c0b5f6a3c11", Name: "hall-light"}
+	action := models.Action{DeviceName: "hall-lamp", CommandName: "switch", Body: `{"on":"false"}`, Delay: "10m"}
+	defer cancelRuleTimers(rule.Id, false)
+
+	if err := scheduleAction(rule, 0, action, triggerContext{TriggerIndex: 0, TriggerState: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := GetAllPendingActions()
+	if len(first) != 1 {
+		t.Fatalf("%d pending actions, want 1", len(first))
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if err := scheduleAction(rule, 0, action, triggerContext{TriggerIndex: 0, TriggerState: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second := GetAllPendingActions()
+	if len(second) != 1 || second[0].Id != first[0].Id {
+		t.Fatalf("pending actions = %+v, want the same action restarted", second)
+	}
+	if second[0].FireAt <= first[0].FireAt {
+		t.Errorf("fire time %d not moved after %d", second[0].FireAt, first[0].FireAt)
+	}
+
+	saved := savedPendingActions(t)
+	if len(saved) != 1 || saved[0].FireAt != second[0].FireAt {
+		t.Errorf("saved pending actions = %+v, want the restarted action", saved)
+	}
+}
+
+func TestScheduleActionInvalidDelay(t *testing.T) {
+	rule := models.Rule{Id: "0c7d3f1a-2b4e-4d5f-8a6b-7c8d9e0f1a2b", Name: "hall-light"}
+	action := models.Action{DeviceName: "hall-lamp", CommandName: "switch", Body: `{"on":"false"}`, Delay: "later"}
+	if err := scheduleAction(rule, 0, action, triggerContext{}); err == nil {
+		t.Fatalf("scheduleAction() with an invalid delay succeeded")
+	}
+	if n := len(GetAllPendingActions()); n != 0 {
+		t.Errorf("%d pending actions, want none", n)
+	}
+}
+
+func TestCancelRuleTimersOnClear(t *testing.T) {
+	rule := models.Rule{Id: "9a1b2c3d-4e5f-4a6b-8c7d-0e1f2a3b4c5d", Name: "porch-light"}
+	other := models.Rule{Id: "1f2e3d4c-5b6a-4978-8695-a4b3c2d1e0f9", Name: "garage-door"}
+	keep := models.Action{DeviceName: "porch-lamp", CommandName: "switch", Body: `{"on":"false"}`, Delay: "10m"}
+	clear := models.Action{DeviceName: "porch-lamp", CommandName: "brightness", Body: `{"level":"10"}`, Delay: "10m", CancelOnClear: true}
+	defer cancelRuleTimers(rule.Id, false)
+	defer cancelRuleTimers(other.Id, false)
+
+	for index, action := range []models.Action{keep, clear} {
+		if err := scheduleAction(rule, index, action, triggerContext{TriggerState: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := scheduleAction(other, 0, clear, triggerContext{TriggerState: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cancelRuleTimers(rule.Id, true)
+
+	pending := GetAllPendingActions()
+	if len(pending) != 2 {
+		t.Fatalf("pending actions = %+v, want the action without cancelOnClear and the action of the other rule", pending)
+	}
+	for _, p := range pending {
+		if p.RuleId == rule.Id && p.Action.CancelOnClear {
+			t.Errorf("action %s with cancelOnClear still pending", p.Id)
+		}
+	}
+	if saved := savedPendingActions(t); len(saved) != 2 {
+		t.Errorf("%d saved pending actions, want 2", len(saved))
+	}
+
+	cancelRuleTimers(rule.Id, false)
+	pending = GetAllPendingActions()
+	if len(pending) != 1 || pending[0].RuleId != other.Id {
+		t.Errorf("pending actions = %+v, want only the action of the other rule", pending)
+	}
+}
+
+func TestPendingActionFiresWithTheTriggerOfItsRule(t *testing.T) {
+	rule := models.Rule{Id: "7d6e5f4a-3b2c-4d1e-9f8a-7b6c5d4e3f2a", Name: "overheat"}
+	loadRules(t, rule)
+	initTriggerQueue(1, 10, cm.DropOldestOverflow)
+	defer func() {
+		stopTriggerQueue()
+		triggers = nil
+	}()
+
+	bodies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies <- string(body)
+	}))
+	defer server.Close()
+
+	action := models.Action{
+		Webhook: &models.WebhookAction{Url: server.URL, Body: `{"value":{{.Value}},"device":"{{.DeviceName}}","resource":"{{.ResourceName}}"}`},
+		Delay:   "20ms",
+	}
+	tc := triggerContext{TriggerIndex: 0, TriggerState: true, Value: 31.5, DeviceName: "thermostat", ResourceName: "temperature"}
+	if err := scheduleAction(rule, 0, action, tc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the rule has no condition anymore, the trigger saved with the action is used
+	want := `{"value":31.5,"device":"thermostat","resource":"temperature"}`
+	select {
+	case body := <-bodies:
+		if body != want {
+			t.Errorf("webhook body = %s, want %s", body, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("delayed action not fired")
+	}
+	if n := len(savedPendingActions(t)); n != 0 {
+		t.Errorf("%d saved pending actions after the action fired, want none", n)
+	}
+}
@@ -0,0 +1,105 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	cm "github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+// executeWaitAction polls the resource of the predicate until it holds or the timeout expires,
+// the OnTimeout actions are run on timeout and their results are recorded in the result
+func executeWaitAction(ctx context.Context, wait *models.WaitAction, tc triggerContext, result *models.ActionResult) error {
+	if wait == nil {
+		return fmt.Errorf("no wait specified")
+	}
+
+	timeout, err := time.ParseDuration(wait.Timeout)
+	if err != nil {
+		return fmt.Errorf("parse timeout '%s' error: %s", wait.Timeout, err.Error())
+	}
+	interval := cm.DefaultWaitInterval
+	if wait.Interval != "" {
+		if interval, err = time.ParseDuration(wait.Interval); err != nil {
+			return fmt.Errorf("parse interval '%s' error: %s", wait.Interval, err.Error())
+		}
+	}
+	if interval <= 0 {
+		interval = cm.DefaultWaitInterval
+	}
+
+	// the wait ends with its own deadline, an expired waitCtx is a timeout while a done ctx cancels the rule
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		value, ok, err := pollPredicate(waitCtx, wait.Predicate, tc)
+		if err != nil {
+			// the device may not answer while it is changing, keep polling until the timeout
+			lastErr = err
+		} else {
+			result.Value = value
+			if ok {
+				return nil
+			}
+		}
+		// the simulated readings do not change while waiting
+		if tc.executor.simulated() {
+			return waitTimeout(ctx, wait, tc, result, lastErr)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return waitTimeout(ctx, wait, tc, result, lastErr)
+		}
+	}
+}
+
+// pollPredicate reads the resource of the predicate with the deadline of an action,
+// a device which does not answer does not hold the wait until its timeout
+func pollPredicate(ctx context.Context, predicate models.Predicate, tc triggerContext) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, actionTimeout)
+	defer cancel()
+	return evaluatePredicate(ctx, predicate, tc)
+}
+
+func waitTimeout(ctx context.Context, wait *models.WaitAction, tc triggerContext, result *models.ActionResult, lastErr error) error {
+	msg := fmt.Sprintf("timeout after %s waiting for resource '%s' of device '%s' %s %s",
+		wait.Timeout, wait.Predicate.ResourceName, wait.Predicate.DeviceName, wait.Predicate.Operator, wait.Predicate.Value)
+	if lastErr != nil {
+		msg = fmt.Sprintf("%s, last error: %s", msg, lastErr.Error())
+	}
+
+	arrError := make([]string, 0)
+	for index, action := range wait.OnTimeout {
+		actionResult := executeAction(ctx, index, action, tc)
+		if actionResult.Status == models.ActionFailed {
+			arrError = append(arrError, fmt.Sprintf("onTimeout[%d]: %s", index, actionResult.Error))
+		}
+		result.Actions = append(result.Actions, actionResult)
+		if stopsSequence(actionResult) {
+			break
+		}
+	}
+	if len(arrError) > 0 {
+		msg = fmt.Sprintf("%s, some timeout actions errored: %s", msg, strings.Join(arrError, "; "))
+	}
+
+	return errors.New(msg)
+}
+
+// stopsSequence returns true if the actions following the action in the list must not be executed
+func stopsSequence(result models.ActionResult) bool {
+	return result.Type == models.WaitActionType && result.Status == models.ActionFailed
+}
@@ -0,0 +1,150 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+func TestExecuteWaitActionUntilThePredicateHolds(t *testing.T) {
+	cc := newFakeCoreCommand()
+	cc.setValue("garage-door", "position", "closing")
+	commandClient = cc
+	defer func() { commandClient = nil }()
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		cc.setValue("garage-door", "position", "closed")
+	}()
+
+	wait := &models.WaitAction{
+		Predicate: models.Predicate{Source: models.ResourcePredicateSource, DeviceName: "garage-door", ResourceName: "position", Operator: "=", Value: "closed"},
+		Interval:  "10ms",
+		Timeout:   "1s",
+		OnTimeout: []models.Action{{DeviceName: "siren", CommandName: "alarm", Body: `{"alarm":"on"}`}},
+	}
+	var result models.ActionResult
+	if err := executeWaitAction(context.Background(), wait, triggerContext{executor: liveExecutor{}}, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Value != "closed" || len(result.Actions) != 0 {
+		t.Errorf("result = %+v, want value closed without timeout actions", result)
+	}
+	if sets := cc.received(); len(sets) != 0 {
+		t.Errorf("timeout actions sent %+v", sets)
+	}
+}
+
+func TestExecuteWaitActionRunsOnTimeoutActions(t *testing.T) {
+	cc := newFakeCoreCommand()
+	cc.setValue("garage-door", "position", "open")
+	commandClient = cc
+	defer func() { commandClient = nil }()
+
+	wait := &models.WaitAction{
+		Predicate: models.Predicate{Source: models.ResourcePredicateSource, DeviceName: "garage-door", ResourceName: "position", Operator: "=", Value: "closed"},
+		Interval:  "10ms",
+		Timeout:   "50ms",
+		OnTimeout: []models.Action{{DeviceName: "siren", CommandName: "alarm", Body: `{"alarm":"on"}`}},
+	}
+	var result models.ActionResult
+	started := time.Now()
+	err := executeWaitAction(context.Background(), wait, triggerContext{executor: liveExecutor{}}, &result)
+	if err == nil || !strings.Contains(err.Error(), "timeout after 50ms waiting for resource 'position' of device 'garage-door' = closed") {
+		t.Fatalf("error = %v, want the wait timeout", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("wait returned after %s", elapsed)
+	}
+	if result.Value != "open" {
+		t.Errorf("last value = %s, want open", result.Value)
+	}
+	if len(result.Actions) != 1 || result.Actions[0].Status != models.ActionSucceeded {
+		t.Fatalf("timeout action results = %+v, want one succeeded action", result.Actions)
+	}
+	sets := cc.received()
+	if len(sets) != 1 || sets[0].deviceName != "siren" || sets[0].settings["alarm"] != "on" {
+		t.Errorf("timeout actions sent %+v, want the siren alarm", sets)
+	}
+}
+
+func TestExecuteWaitActionCancelled(t *testing.T) {
+	cc := newFakeCoreCommand()
+	cc.setValue("garage-door", "position", "open")
+	commandClient = cc
+	defer func() { commandClient = nil }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(30*time.Millisecond, cancel)
+	wait := &models.WaitAction{
+		Predicate: models.Predicate{Source: models.ResourcePredicateSource, DeviceName: "garage-door", ResourceName: "position", Operator: "=", Value: "closed"},
+		Interval:  "10ms",
+		Timeout:   "1s",
+		OnTimeout: []models.Action{{DeviceName: "siren", CommandName: "alarm", Body: `{"alarm":"on"}`}},
+	}
+	var result models.ActionResult
+	if err := executeWaitAction(ctx, wait, triggerContext{executor: liveExecutor{}}, &result); err != context.Canceled {
+		t.Fatalf("error = %v, want %v", err, context.Canceled)
+	}
+	if sets := cc.received(); len(sets) != 0 {
+		t.Errorf("timeout actions sent %+v after a cancellation", sets)
+	}
+}
+
+func TestExecuteWaitActionDeviceNotAnswering(t *testing.T) {
+	cc := newFakeCoreCommand()
+	cc.setValue("garage-door", "position", "open")
+	cc.hang("garage-door")
+	commandClient = cc
+	defer func() { commandClient = nil }()
+
+	wait := &models.WaitAction{
+		Predicate: models.Predicate{Source: models.ResourcePredicateSource, DeviceName: "garage-door", ResourceName: "position", Operator: "=", Value: "closed"},
+		Interval:  "10ms",
+		Timeout:   "50ms",
+		OnTimeout: []models.Action{{DeviceName: "siren", CommandName: "alarm", Body: `{"alarm":"on"}`}},
+	}
+	var result models.ActionResult
+	started := time.Now()
+	err := executeWaitAction(context.Background(), wait, triggerContext{executor: liveExecutor{}}, &result)
+	if err == nil || !strings.Contains(err.Error(), "timeout after 50ms") || !strings.Contains(err.Error(), "did not answer") {
+		t.Fatalf("error = %v, want the wait timeout with the read error", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("wait returned after %s", elapsed)
+	}
+	// the timeout of the wait is not a cancellation of the rule
+	if sets := cc.received(); len(sets) != 1 || sets[0].deviceName != "siren" {
+		t.Errorf("timeout actions sent %+v, want the siren alarm", sets)
+	}
+}
+
+func TestExecuteWaitActionPollDeadline(t *testing.T) {
+	cc := newFakeCoreCommand()
+	cc.setValue("garage-door", "position", "closed")
+	cc.hang("garage-door")
+	commandClient = cc
+	defer func() { commandClient = nil }()
+
+	defer func(timeout time.Duration) { actionTimeout = timeout }(actionTimeout)
+	actionTimeout = 20 * time.Millisecond
+	time.AfterFunc(50*time.Millisecond, func() { cc.answer("garage-door") })
+
+	wait := &models.WaitAction{
+		Predicate: models.Predicate{Source: models.ResourcePredicateSource, DeviceName: "garage-door", ResourceName: "position", Operator: "=", Value: "closed"},
+		Interval:  "10ms",
+		Timeout:   "5s",
+	}
+	var result models.ActionResult
+	started := time.Now()
+	if err := executeWaitAction(context.Background(), wait, triggerContext{executor: liveExecutor{}}, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the first reads are abandoned after the action timeout instead of holding the wait
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("wait returned after %s", elapsed)
+	}
+}
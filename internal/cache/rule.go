@@ -1,12 +1,15 @@
 package cache
 
 import (
+	"reflect"
 	"sync"
+	"time"
 
 	"github.com/edgexfoundry/device-sdk-go/v2/pkg/service"
-	ctModels "github.com/edgexfoundry/go-mod-core-contracts/v2/models"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
 	"github.com/rddigital/device-scenario/internal/common"
 	"github.com/rddigital/device-scenario/internal/models"
+	"github.com/rddigital/device-scenario/internal/store"
 )
 
 type RuleCache interface {
@@ -17,58 +20,125 @@ type RuleCache interface {
 	Add(rule models.Rule)
 	Update(rule models.Rule)
 	RemoveByName(name string)
-	UpdateStateRule(id string, index int, state bool)
+	UpdateStateRule(id string, index int, state bool, source string)
 	GetStateRule(id string, index int) bool
+	GetStatesRule(id string) []bool
+	GetChangesRule(id string) []models.ConditionChange
+	GetConditionStatesRule(id string) []string
+	UpdateFiredRule(id string)
+	GetFiredRule(id string) int64
 }
 
 type ruleCache struct {
 	ruleMap   map[string]models.Rule // key is rule id
 	nameIdMap map[string]string
 	stateMap  map[string][]bool
+	changeMap map[string][]models.ConditionChange
+	firedMap  map[string]int64 // last time the rule fired, in nanoseconds
+	persist   *store.DebouncedSave
 	mutex     sync.RWMutex
 }
 
 var (
 	rc *ruleCache
+	lc logger.LoggingClient
 )
 
 func Rules() RuleCache {
 	return rc
 }
 
-// InitCache Init basic state for cache
-func InitCache() {
+// InitCache Init basic state for cache, the condition states saved before the restart are restored
+func InitCache(l logger.LoggingClient) {
 	ds := service.RunningService()
 	devices := ds.Devices()
-	scenarios := make([]ctModels.Device, 0, len(devices))
+	rules := make([]models.Rule, 0, len(devices))
 
 	for _, d := range devices {
-		if d.ProfileName == common.AutoScenarioProfile {
-			scenarios = append(scenarios, d)
+		if d.ProfileName != common.AutoScenarioProfile {
+			continue
+		}
+		if rule, ok := models.RuleFromDevice(d); ok {
+			rules = append(rules, rule)
+		} else {
+			// Remove scenarios are not valid
+			ds.RemoveDeviceByName(d.Name)
 		}
 	}
 
-	sizeMap := len(scenarios) + 1 // minimum = 1
+	InitCacheFromRules(l, rules)
+}
+
+// InitCacheFromRules Init basic state for cache with the given rules, the condition states saved
+// before the restart are restored
+func InitCacheFromRules(l logger.LoggingClient, rules []models.Rule) {
+	lc = l
+
+	sizeMap := len(rules) + 1 // minimum = 1
 	ruleMap := make(map[string]models.Rule, sizeMap)
 	nameIdMap := make(map[string]string, sizeMap)
 	stateMap := make(map[string][]bool, sizeMap)
+	changeMap := make(map[string][]models.ConditionChange, sizeMap)
 
-	for _, s := range scenarios {
-		if rule, ok := models.RuleFromDevice(s); ok {
-			ruleMap[rule.Id] = rule
-			nameIdMap[rule.Name] = rule.Id
-			stateMap[rule.Id] = make([]bool, len(rule.Conditions))
-		} else {
-			// Remove scenarios are not valid
-			ds.RemoveDeviceByName(s.Name)
-		}
+	for _, rule := range rules {
+		ruleMap[rule.Id] = rule
+		nameIdMap[rule.Name] = rule.Id
+		stateMap[rule.Id] = make([]bool, len(rule.Conditions))
+		changeMap[rule.Id] = make([]models.ConditionChange, len(rule.Conditions))
 	}
 
 	rc = &ruleCache{
 		ruleMap:   ruleMap,
 		nameIdMap: nameIdMap,
 		stateMap:  stateMap,
+		changeMap: changeMap,
+		firedMap:  make(map[string]int64, sizeMap),
+	}
+	rc.persist = store.NewDebouncedSave(common.StatesStoreName, common.DefaultPersistDelay, rc.snapshot, func(err error) {
+		lc.Errorf("save condition states error: %s", err.Error())
+	})
+	rc.restore()
+}
+
+// Flush saves the condition states at once if a save is scheduled, e.g. when the service stops
+func Flush() {
+	if rc != nil {
+		rc.persist.Flush()
+	}
+}
+
+// restore sets the states saved before the restart, the states of the rules whose conditions
+// changed are not restored. A schedule condition is always restored false, its state only holds
+// during the evaluation of its callback
+func (rc *ruleCache) restore() {
+	var arrState []models.RuleState
+	if _, err := store.Local().Load(common.StatesStoreName, &arrState); err != nil {
+		lc.Errorf("load condition states error: %s", err.Error())
+		return
 	}
+
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	for _, saved := range arrState {
+		rule, ok := rc.ruleMap[saved.RuleId]
+		if !ok {
+			continue
+		}
+		rc.firedMap[rule.Id] = saved.LastFired
+		if !reflect.DeepEqual(rule.Conditions, saved.Conditions) ||
+			len(saved.States) != len(rule.Conditions) || len(saved.Changes) != len(rule.Conditions) {
+			lc.Debugf("conditions of rule '%s' changed, states not restored", rule.Name)
+			continue
+		}
+		for index, c := range rule.Conditions {
+			if c.Type == common.ThresholdRuleType {
+				rc.stateMap[rule.Id][index] = saved.States[index]
+			}
+			rc.changeMap[rule.Id][index] = saved.Changes[index]
+		}
+	}
+	rc.save()
 }
 
 func (rc *ruleCache) CheckExistsById(id string) bool {
@@ -126,11 +196,17 @@ func (rc *ruleCache) Update(rule models.Rule) {
 }
 
 func (rc *ruleCache) update(rule models.Rule) {
+	fired := rc.firedMap[rule.Id]
 	rc.delete(rule.Name)
 
 	rc.nameIdMap[rule.Name] = rule.Id
 	rc.ruleMap[rule.Id] = rule
 	rc.stateMap[rule.Id] = make([]bool, len(rule.Conditions))
+	rc.changeMap[rule.Id] = make([]models.ConditionChange, len(rule.Conditions))
+	if fired > 0 {
+		rc.firedMap[rule.Id] = fired
+	}
+	rc.save()
 }
 
 func (rc *ruleCache) delete(name string) {
@@ -142,6 +218,8 @@ func (rc *ruleCache) delete(name string) {
 	delete(rc.nameIdMap, name)
 	delete(rc.ruleMap, id)
 	delete(rc.stateMap, id)
+	delete(rc.changeMap, id)
+	delete(rc.firedMap, id)
 }
 
 func (rc *ruleCache) RemoveByName(name string) {
@@ -149,16 +227,28 @@ func (rc *ruleCache) RemoveByName(name string) {
 	defer rc.mutex.Unlock()
 
 	rc.delete(name)
+	rc.save()
 }
 
-func (rc *ruleCache) UpdateStateRule(id string, index int, state bool) {
+// UpdateStateRule sets the state of the condition, source is what updated it.
+// The states are saved when a state changes, not on every update
+func (rc *ruleCache) UpdateStateRule(id string, index int, state bool, source string) {
 	rc.mutex.Lock()
 	defer rc.mutex.Unlock()
 
 	if len(rc.stateMap[id]) <= index {
 		return
 	}
-	rc.stateMap[id][index] = state
+
+	now := time.Now().UnixNano()
+	change := &rc.changeMap[id][index]
+	change.LastUpdated = now
+	if rc.stateMap[id][index] != state || change.LastChanged == 0 {
+		change.LastChanged = now
+		change.Source = source
+		rc.stateMap[id][index] = state
+		rc.save()
+	}
 }
 
 func (rc *ruleCache) GetStateRule(id string, index int) bool {
@@ -170,3 +260,88 @@ func (rc *ruleCache) GetStateRule(id string, index int) bool {
 	}
 	return rc.stateMap[id][index]
 }
+
+// GetStatesRule returns a copy of the states of all conditions of the rule
+func (rc *ruleCache) GetStatesRule(id string) []bool {
+	rc.mutex.RLock()
+	defer rc.mutex.RUnlock()
+
+	states := make([]bool, len(rc.stateMap[id]))
+	copy(states, rc.stateMap[id])
+	return states
+}
+
+// GetConditionStatesRule returns the states of all conditions of the rule, "true" or "false", or the
+// expiry state of a condition which was not updated for its max age
+func (rc *ruleCache) GetConditionStatesRule(id string) []string {
+	rc.mutex.RLock()
+	defer rc.mutex.RUnlock()
+
+	rule := rc.ruleMap[id]
+	now := time.Now()
+	states := make([]string, len(rc.stateMap[id]))
+	for index, state := range rc.stateMap[id] {
+		states[index] = models.ConditionStateOf(state)
+		if index < len(rule.Conditions) && rule.Conditions[index].Expired(rc.changeMap[id][index].LastUpdated, now) {
+			states[index] = rule.Conditions[index].ExpiryState()
+		}
+	}
+	return states
+}
+
+// GetChangesRule returns a copy of the last changes of all conditions of the rule
+func (rc *ruleCache) GetChangesRule(id string) []models.ConditionChange {
+	rc.mutex.RLock()
+	defer rc.mutex.RUnlock()
+
+	changes := make([]models.ConditionChange, len(rc.changeMap[id]))
+	copy(changes, rc.changeMap[id])
+	return changes
+}
+
+// UpdateFiredRule sets the last time the rule fired to now
+func (rc *ruleCache) UpdateFiredRule(id string) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	if _, ok := rc.ruleMap[id]; !ok {
+		return
+	}
+	rc.firedMap[id] = time.Now().UnixNano()
+	rc.save()
+}
+
+// GetFiredRule returns the last time the rule fired in nanoseconds, 0 if it never fired
+func (rc *ruleCache) GetFiredRule(id string) int64 {
+	rc.mutex.RLock()
+	defer rc.mutex.RUnlock()
+
+	return rc.firedMap[id]
+}
+
+// save schedules the save of the condition states, it must be called with the lock held
+func (rc *ruleCache) save() {
+	rc.persist.Schedule()
+}
+
+// snapshot returns a copy of the condition states to save
+func (rc *ruleCache) snapshot() interface{} {
+	rc.mutex.RLock()
+	defer rc.mutex.RUnlock()
+
+	arrState := make([]models.RuleState, 0, len(rc.ruleMap))
+	for id, rule := range rc.ruleMap {
+		states := make([]bool, len(rc.stateMap[id]))
+		copy(states, rc.stateMap[id])
+		changes := make([]models.ConditionChange, len(rc.changeMap[id]))
+		copy(changes, rc.changeMap[id])
+		arrState = append(arrState, models.RuleState{
+			RuleId:     id,
+			Conditions: rule.Conditions,
+			States:     states,
+			Changes:    changes,
+			LastFired:  rc.firedMap[id],
+		})
+	}
+	return arrState
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/logger"
+
+	"github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+	"github.com/rddigital/device-scenario/internal/store"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "device-scenario-cache")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err = store.InitStore(dir); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// restart saves the states as the service does when it stops, then loads the rules again
+func restart(rules ...models.Rule) {
+	Flush()
+	InitCacheFromRules(logger.NewMockClient(), rules)
+}
+
+func TestInitCacheRestoresConditionStates(t *testing.T) {
+	Flush()
+	if err := store.Local().Delete(common.StatesStoreName); err != nil {
+		t.Fatalf("delete condition states error: %v", err)
+	}
+
+	hot := models.Condition{Logic: common.AndLogic, Type: common.ThresholdRuleType, DeviceThreshold: "thermostat", ResourceThreshold: "temperature", OperatorThreshold: ">", ValueThreshold: "30"}
+	hourly := models.Condition{Logic: common.AndLogic, Type: common.ScheduleRuleType, IntervalTime: "1h"}
+	cooling := models.Rule{Id: "cooling", Name: "cooling", Conditions: []models.Condition{hot, hourly}}
+	alarm := models.Rule{Id: "alarm", Name: "alarm", Conditions: []models.Condition{hot}}
+
+	restart(cooling, alarm)
+	Rules().UpdateStateRule(cooling.Id, 0, true, "thermostat")
+	Rules().UpdateStateRule(cooling.Id, 1, true, "scheduler")
+	Rules().UpdateStateRule(alarm.Id, 0, true, "thermostat")
+	Rules().UpdateFiredRule(alarm.Id)
+	fired := Rules().GetFiredRule(alarm.Id)
+	changes := Rules().GetChangesRule(cooling.Id)
+
+	// the threshold of the alarm changed while the service was stopped
+	changed := alarm
+	changed.Conditions = []models.Condition{hot}
+	changed.Conditions[0].ValueThreshold = "35"
+	restart(cooling, changed)
+
+	if got := Rules().GetStatesRule(cooling.Id); len(got) != 2 || !got[0] || got[1] {
+		t.Errorf("states of the unchanged rule = %v, want [true false]: the schedule is not restored", got)
+	}
+	if got := Rules().GetChangesRule(cooling.Id); got[0] != changes[0] {
+		t.Errorf("last change = %+v, want %+v", got[0], changes[0])
+	}
+	if got := Rules().GetStatesRule(alarm.Id); len(got) != 1 || got[0] {
+		t.Errorf("states of the changed rule = %v, want [false]", got)
+	}
+	if got := Rules().GetFiredRule(alarm.Id); got != fired {
+		t.Errorf("last fired = %d, want %d restored even if the conditions changed", got, fired)
+	}
+}
+
+func TestInitCacheIgnoresStatesOfRemovedRules(t *testing.T) {
+	Flush()
+	if err := store.Local().Delete(common.StatesStoreName); err != nil {
+		t.Fatalf("delete condition states error: %v", err)
+	}
+
+	hot := models.Condition{Logic: common.AndLogic, Type: common.ThresholdRuleType, DeviceThreshold: "thermostat", ResourceThreshold: "temperature", OperatorThreshold: ">", ValueThreshold: "30"}
+	alarm := models.Rule{Id: "alarm", Name: "alarm", Conditions: []models.Condition{hot}}
+	restart(alarm)
+	Rules().UpdateStateRule(alarm.Id, 0, true, "thermostat")
+
+	restart()
+	if Rules().CheckExistsById(alarm.Id) {
+		t.Fatalf("removed rule restored")
+	}
+
+	// the rule is added again with the same id
+	restart(alarm)
+	if got := Rules().GetStatesRule(alarm.Id); got[0] {
+		t.Errorf("states = %v, want the states dropped with the rule", got)
+	}
+}
+
+func TestGetConditionStatesRuleExpires(t *testing.T) {
+	door := models.Condition{Logic: common.AndLogic, Type: common.ThresholdRuleType, DeviceThreshold: "door", ResourceThreshold: "open", OperatorThreshold: "=", ValueThreshold: "1", MaxAge: "20ms"}
+	motion := door
+	motion.DeviceThreshold, motion.OnExpiry = "motion", models.ConditionFalse
+	lamp := door
+	lamp.DeviceThreshold, lamp.MaxAge = "lamp", ""
+	rule := models.Rule{Id: "intrusion", Name: "intrusion", Conditions: []models.Condition{door, motion, lamp}}
+
+	restart(rule)
+	for index := range rule.Conditions {
+		Rules().UpdateStateRule(rule.Id, index, true, "sensor")
+	}
+	if got := Rules().GetConditionStatesRule(rule.Id); got[0] != models.ConditionTrue || got[1] != models.ConditionTrue {
+		t.Fatalf("states = %v, want the fresh states true", got)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	got := Rules().GetConditionStatesRule(rule.Id)
+	want := []string{models.ConditionUnknown, models.ConditionFalse, models.ConditionTrue}
+	for index := range want {
+		if got[index] != want[index] {
+			t.Errorf("states = %v, want %v", got, want)
+			break
+		}
+	}
+
+	// a reading with the same state refreshes the condition
+	Rules().UpdateStateRule(rule.Id, 0, true, "sensor")
+	if got = Rules().GetConditionStatesRule(rule.Id); got[0] != models.ConditionTrue {
+		t.Errorf("refreshed state = %s, want true", got[0])
+	}
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/clients/interfaces"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	dtoCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/responses"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+)
+
+// HttpCommandClient is a CommandClient which cancels its requests with the context, unlike the client
+// of go-mod-core-contracts. The headers are sent with each request, e.g. the authorization header
+// of the API gateway of a remote EdgeX instance
+type HttpCommandClient struct {
+	baseUrl string
+	headers map[string]string
+}
+
+func NewHttpCommandClient(baseUrl string, headers map[string]string) interfaces.CommandClient {
+	return &HttpCommandClient{
+		baseUrl: baseUrl,
+		headers: headers,
+	}
+}
+
+func (c *HttpCommandClient) AllDeviceCoreCommands(ctx context.Context, offset int, limit int) (res responses.MultiDeviceCoreCommandsResponse, err errors.EdgeX) {
+	requestParams := url.Values{}
+	requestParams.Set(common.Offset, strconv.Itoa(offset))
+	requestParams.Set(common.Limit, strconv.Itoa(limit))
+	err = c.request(ctx, http.MethodGet, common.ApiAllDeviceRoute, requestParams, nil, &res)
+	return res, err
+}
+
+func (c *HttpCommandClient) DeviceCoreCommandsByDeviceName(ctx context.Context, deviceName string) (res responses.DeviceCoreCommandResponse, err errors.EdgeX) {
+	requestPath := path.Join(common.ApiDeviceRoute, common.Name, url.QueryEscape(deviceName))
+	err = c.request(ctx, http.MethodGet, requestPath, nil, nil, &res)
+	return res, err
+}
+
+func (c *HttpCommandClient) IssueGetCommandByName(ctx context.Context, deviceName string, commandName string, dsPushEvent string, dsReturnEvent string) (res *responses.EventResponse, err errors.EdgeX) {
+	requestParams := url.Values{}
+	requestParams.Set(common.PushEvent, dsPushEvent)
+	requestParams.Set(common.ReturnEvent, dsReturnEvent)
+	requestPath := path.Join(common.ApiDeviceRoute, common.Name, url.QueryEscape(deviceName), url.QueryEscape(commandName))
+	err = c.request(ctx, http.MethodGet, requestPath, requestParams, nil, &res)
+	return res, err
+}
+
+func (c *HttpCommandClient) IssueSetCommandByName(ctx context.Context, deviceName string, commandName string, settings map[string]string) (res dtoCommon.BaseResponse, err errors.EdgeX) {
+	requestPath := path.Join(common.ApiDeviceRoute, common.Name, url.QueryEscape(deviceName), url.QueryEscape(commandName))
+	err = c.request(ctx, http.MethodPut, requestPath, nil, settings, &res)
+	return res, err
+}
+
+// request sends the request with the headers of the client and decodes the response into res
+func (c *HttpCommandClient) request(ctx context.Context, method string, requestPath string, requestParams url.Values, data interface{}, res interface{}) errors.EdgeX {
+	return requestJSON(ctx, "core-command", c.baseUrl, method, requestPath, requestParams, c.headers, data, res)
+}
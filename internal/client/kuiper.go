@@ -2,6 +2,7 @@ package client
 
 import (
 	"encoding/json"
+	"time"
 )
 
 type RuleEngineClient interface {
@@ -26,21 +27,23 @@ type RuleEngineClient interface {
 
 type KuiperRuleClient struct {
 	baseUrl string
+	timeout time.Duration
 }
 
-func NewKuiperRuleClient(baseUrl string) RuleEngineClient {
+func NewKuiperRuleClient(baseUrl string, timeout time.Duration) RuleEngineClient {
 	return &KuiperRuleClient{
 		baseUrl: baseUrl,
+		timeout: timeout,
 	}
 }
 
 func (c *KuiperRuleClient) CreateStream(stream string) (string, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "streams", "POST", []byte(stream))
+	dataResponse, err := SendRequest(c.baseUrl, "streams", "POST", []byte(stream), c.timeout)
 	return string(dataResponse), err
 }
 
 func (c *KuiperRuleClient) ShowStreams() ([]string, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "streams", "GET", nil)
+	dataResponse, err := SendRequest(c.baseUrl, "streams", "GET", nil, c.timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -54,27 +57,27 @@ func (c *KuiperRuleClient) ShowStreams() ([]string, error) {
 }
 
 func (c *KuiperRuleClient) DescribeStream(name string) (string, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "streams/"+name, "GET", nil)
+	dataResponse, err := SendRequest(c.baseUrl, "streams/"+name, "GET", nil, c.timeout)
 	return string(dataResponse), err
 }
 
 func (c *KuiperRuleClient) UpdateStream(name string, stream string) (string, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "streams/"+name, "PUT", []byte(stream))
+	dataResponse, err := SendRequest(c.baseUrl, "streams/"+name, "PUT", []byte(stream), c.timeout)
 	return string(dataResponse), err
 }
 
 func (c *KuiperRuleClient) DropStream(name string) (string, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "streams/"+name, "DELETE", nil)
+	dataResponse, err := SendRequest(c.baseUrl, "streams/"+name, "DELETE", nil, c.timeout)
 	return string(dataResponse), err
 }
 
 func (c *KuiperRuleClient) CreateRule(rule string) (string, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "rules", "POST", []byte(rule))
+	dataResponse, err := SendRequest(c.baseUrl, "rules", "POST", []byte(rule), c.timeout)
 	return string(dataResponse), err
 }
 
 func (c *KuiperRuleClient) ShowRules() ([]map[string]interface{}, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "rules", "GET", nil)
+	dataResponse, err := SendRequest(c.baseUrl, "rules", "GET", nil, c.timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -88,36 +91,36 @@ func (c *KuiperRuleClient) ShowRules() ([]map[string]interface{}, error) {
 }
 
 func (c *KuiperRuleClient) DescribeRule(name string) (string, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name, "GET", nil)
+	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name, "GET", nil, c.timeout)
 	return string(dataResponse), err
 }
 
 func (c *KuiperRuleClient) UpdateRule(name string, rule string) (string, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name, "PUT", []byte(rule))
+	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name, "PUT", []byte(rule), c.timeout)
 	return string(dataResponse), err
 }
 
 func (c *KuiperRuleClient) DropRule(name string) (string, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name, "DELETE", nil)
+	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name, "DELETE", nil, c.timeout)
 	return string(dataResponse), err
 }
 
 func (c *KuiperRuleClient) StatusRule(name string) (string, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name+"/status", "GET", nil)
+	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name+"/status", "GET", nil, c.timeout)
 	return string(dataResponse), err
 }
 
 func (c *KuiperRuleClient) StartRule(name string) (string, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name+"/start", "POST", nil)
+	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name+"/start", "POST", nil, c.timeout)
 	return string(dataResponse), err
 }
 
 func (c *KuiperRuleClient) StopRule(name string) (string, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name+"/stop", "POST", nil)
+	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name+"/stop", "POST", nil, c.timeout)
 	return string(dataResponse), err
 }
 
 func (c *KuiperRuleClient) RestartRule(name string) (string, error) {
-	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name+"/restart", "POST", nil)
+	dataResponse, err := SendRequest(c.baseUrl, "rules/"+name+"/restart", "POST", nil, c.timeout)
 	return string(dataResponse), err
 }
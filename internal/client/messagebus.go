@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/edgexfoundry/go-mod-messaging/v2/messaging"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+)
+
+const (
+	clientIdOptionKey = "ClientId"
+)
+
+type MessageBusClient interface {
+	Publish(ctx context.Context, topic string, payload []byte, qos int, retained bool) error
+	Disconnect() error
+}
+
+// NewMessageBusClient returns the client of the message bus of the configuration, the QoS and retained flag
+// of the messages are only used by the MQTT message bus
+func NewMessageBusClient(config types.MessageBusConfig) MessageBusClient {
+	if strings.EqualFold(config.Type, messaging.MQTT) {
+		return NewMqttMessageBusClient(config)
+	}
+	return NewEdgexMessageBusClient(config)
+}
+
+// EdgexMessageBusClient publishes messages through the EdgeX message bus, which ignores the QoS and retained flag.
+type EdgexMessageBusClient struct {
+	config types.MessageBusConfig
+	client messaging.MessageClient
+	mutex  sync.Mutex
+}
+
+func NewEdgexMessageBusClient(config types.MessageBusConfig) MessageBusClient {
+	return &EdgexMessageBusClient{config: config}
+}
+
+func (c *EdgexMessageBusClient) Publish(ctx context.Context, topic string, payload []byte, qos int, retained bool) error {
+	messageClient, err := c.messageClient()
+	if err != nil {
+		return err
+	}
+
+	envelope := types.NewMessageEnvelope(payload, ctx)
+	envelope.ContentType = ContentTypeJSON
+	return messageClient.Publish(envelope, topic)
+}
+
+func (c *EdgexMessageBusClient) Disconnect() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.client == nil {
+		return nil
+	}
+	err := c.client.Disconnect()
+	c.client = nil
+	if err != nil {
+		return fmt.Errorf("disconnect message bus error: %s", err.Error())
+	}
+	return nil
+}
+
+// messageClient returns the connection to the message bus. The connection is made without holding the lock,
+// so an unreachable broker never blocks the disconnection
+func (c *EdgexMessageBusClient) messageClient() (messaging.MessageClient, error) {
+	c.mutex.Lock()
+	messageClient := c.client
+	c.mutex.Unlock()
+	if messageClient != nil {
+		return messageClient, nil
+	}
+
+	messageClient, err := messaging.NewMessageClient(c.config)
+	if err != nil {
+		return nil, fmt.Errorf("create message bus client error: %s", err.Error())
+	}
+	if err = messageClient.Connect(); err != nil {
+		return nil, fmt.Errorf("connect message bus error: %s", err.Error())
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// another publish connected meanwhile
+	if c.client != nil {
+		_ = messageClient.Disconnect()
+		return c.client, nil
+	}
+	c.client = messageClient
+	return messageClient, nil
+}
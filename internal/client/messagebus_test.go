@@ -0,0 +1,233 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+)
+
+// brokerMessage is a PUBLISH packet received by the test broker
+type brokerMessage struct {
+	clientId string
+	topic    string
+	payload  []byte
+	qos      byte
+	retained bool
+}
+
+// testBroker is a minimal MQTT 3.1.1 broker which accepts any connection and records the
+// published messages, it does not deliver them to subscribers
+type testBroker struct {
+	listener  net.Listener
+	messages  chan brokerMessage
+	mutex     sync.Mutex
+	clientIds []string
+}
+
+func newTestBroker(t *testing.T) *testBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen error: %v", err)
+	}
+	b := &testBroker{listener: listener, messages: make(chan brokerMessage, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return b
+}
+
+func (b *testBroker) config() types.MessageBusConfig {
+	addr := b.listener.Addr().(*net.TCPAddr)
+	return types.MessageBusConfig{
+		PublishHost: types.HostInfo{Host: addr.IP.String(), Port: addr.Port, Protocol: "tcp"},
+		Type:        "mqtt",
+		Optional:    map[string]string{clientIdOptionKey: "device-scenario"},
+	}
+}
+
+func (b *testBroker) connections() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return append([]string(nil), b.clientIds...)
+}
+
+func (b *testBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	var clientId string
+	for {
+		header, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch header >> 4 {
+		case 1: // CONNECT: protocol name, level, flags and keep alive, then the client id
+			nameLength := int(binary.BigEndian.Uint16(body))
+			payload := body[2+nameLength+4:]
+			clientId = string(payload[2 : 2+binary.BigEndian.Uint16(payload)])
+			b.mutex.Lock()
+			b.clientIds = append(b.clientIds, clientId)
+			b.mutex.Unlock()
+			conn.Write([]byte{0x20, 0x02, 0x00, 0x00})
+		case 3: // PUBLISH
+			qos := (header >> 1) & 0x03
+			topicLength := int(binary.BigEndian.Uint16(body))
+			topic := string(body[2 : 2+topicLength])
+			rest := body[2+topicLength:]
+			if qos > 0 {
+				id := rest[:2]
+				rest = rest[2:]
+				if qos == 1 {
+					conn.Write([]byte{0x40, 0x02, id[0], id[1]})
+				} else {
+					conn.Write([]byte{0x50, 0x02, id[0], id[1]})
+				}
+			}
+			b.messages <- brokerMessage{clientId: clientId, topic: topic, payload: rest, qos: qos, retained: header&0x01 == 1}
+		case 6: // PUBREL
+			conn.Write([]byte{0x70, 0x02, body[0], body[1]})
+		case 12: // PINGREQ
+			conn.Write([]byte{0xd0, 0x00})
+		case 14: // DISCONNECT
+			return
+		}
+	}
+}
+
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7f) * multiplier
+		multiplier *= 128
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	if _, err = io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func (b *testBroker) next(t *testing.T) brokerMessage {
+	t.Helper()
+	select {
+	case m := <-b.messages:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no message received by the broker")
+		return brokerMessage{}
+	}
+}
+
+func TestMqttMessageBusClientPublish(t *testing.T) {
+	broker := newTestBroker(t)
+	c := NewMessageBusClient(broker.config())
+	defer c.Disconnect()
+
+	tests := []struct {
+		topic    string
+		qos      int
+		retained bool
+	}{
+		{"scenario/alarm", 1, true},
+		{"scenario/status", 0, false},
+		{"scenario/alarm", 1, true},
+		{"scenario/ack", 2, false},
+		{"scenario/state", 0, true},
+	}
+	for _, tt := range tests {
+		if err := c.Publish(context.Background(), tt.topic, []byte(`{"state":"on"}`), tt.qos, tt.retained); err != nil {
+			t.Fatalf("Publish(%s) unexpected error: %v", tt.topic, err)
+		}
+
+		m := broker.next(t)
+		if m.topic != tt.topic || int(m.qos) != tt.qos || m.retained != tt.retained {
+			t.Errorf("broker received topic %s qos %d retained %t, want %s qos %d retained %t",
+				m.topic, m.qos, m.retained, tt.topic, tt.qos, tt.retained)
+		}
+		var envelope types.MessageEnvelope
+		if err := json.Unmarshal(m.payload, &envelope); err != nil {
+			t.Fatalf("payload is not a message envelope: %v", err)
+		}
+		if string(envelope.Payload) != `{"state":"on"}` || envelope.ContentType != ContentTypeJSON {
+			t.Errorf("envelope payload %s, content type %s", envelope.Payload, envelope.ContentType)
+		}
+	}
+
+	// a single connection publishes all the QoS and retained flags
+	if got := broker.connections(); len(got) != 1 || got[0] != "device-scenario" {
+		t.Errorf("broker connections = %v, want [device-scenario]", got)
+	}
+}
+
+func TestMqttMessageBusClientConnectError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen error: %v", err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	listener.Close()
+
+	c := NewMessageBusClient(types.MessageBusConfig{
+		PublishHost: types.HostInfo{Host: addr.IP.String(), Port: addr.Port, Protocol: "tcp"},
+		Type:        "mqtt",
+		Optional:    map[string]string{"ConnectTimeout": "1"},
+	})
+	err = c.Publish(context.Background(), "scenario/alarm", []byte("{}"), 0, false)
+	if err == nil || !strings.Contains(err.Error(), "connect message bus error") {
+		t.Fatalf("Publish() error = %v, want a connect error", err)
+	}
+}
+
+func TestMqttMessageBusClientConcurrentPublish(t *testing.T) {
+	broker := newTestBroker(t)
+	c := NewMessageBusClient(broker.config())
+	defer c.Disconnect()
+
+	const publishers = 5
+	errs := make(chan error, publishers)
+	for i := 0; i < publishers; i++ {
+		go func() {
+			errs <- c.Publish(context.Background(), "scenario/alarm", []byte(`{"state":"on"}`), 1, false)
+		}()
+	}
+	for i := 0; i < publishers; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("Publish() unexpected error: %v", err)
+		}
+		broker.next(t)
+	}
+
+	// the connections made meanwhile by the other publishers are closed, the client keeps one
+	c.(*MqttMessageBusClient).mutex.Lock()
+	defer c.(*MqttMessageBusClient).mutex.Unlock()
+	if c.(*MqttMessageBusClient).client == nil {
+		t.Errorf("mqtt client not kept")
+	}
+}
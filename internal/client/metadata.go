@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/responses"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+)
+
+// MetadataClient queries the devices and the profiles of core-metadata, the requests are cancelled with the context
+type MetadataClient interface {
+	AllDevices(ctx context.Context, labels []string, offset int, limit int) (responses.MultiDevicesResponse, errors.EdgeX)
+	DeviceByName(ctx context.Context, name string) (responses.DeviceResponse, errors.EdgeX)
+	DevicesByProfileName(ctx context.Context, name string, offset int, limit int) (responses.MultiDevicesResponse, errors.EdgeX)
+	DeviceProfileByName(ctx context.Context, name string) (responses.DeviceProfileResponse, errors.EdgeX)
+}
+
+type HttpMetadataClient struct {
+	baseUrl string
+}
+
+func NewHttpMetadataClient(baseUrl string) MetadataClient {
+	return &HttpMetadataClient{
+		baseUrl: baseUrl,
+	}
+}
+
+func (c *HttpMetadataClient) AllDevices(ctx context.Context, labels []string, offset int, limit int) (res responses.MultiDevicesResponse, err errors.EdgeX) {
+	requestParams := url.Values{}
+	if len(labels) > 0 {
+		requestParams.Set(common.Labels, strings.Join(labels, common.CommaSeparator))
+	}
+	requestParams.Set(common.Offset, strconv.Itoa(offset))
+	requestParams.Set(common.Limit, strconv.Itoa(limit))
+	err = c.request(ctx, common.ApiAllDeviceRoute, requestParams, &res)
+	return res, err
+}
+
+func (c *HttpMetadataClient) DeviceByName(ctx context.Context, name string) (res responses.DeviceResponse, err errors.EdgeX) {
+	requestPath := path.Join(common.ApiDeviceRoute, common.Name, url.QueryEscape(name))
+	err = c.request(ctx, requestPath, nil, &res)
+	return res, err
+}
+
+func (c *HttpMetadataClient) DevicesByProfileName(ctx context.Context, name string, offset int, limit int) (res responses.MultiDevicesResponse, err errors.EdgeX) {
+	requestPath := path.Join(common.ApiDeviceRoute, common.Profile, common.Name, url.QueryEscape(name))
+	requestParams := url.Values{}
+	requestParams.Set(common.Offset, strconv.Itoa(offset))
+	requestParams.Set(common.Limit, strconv.Itoa(limit))
+	err = c.request(ctx, requestPath, requestParams, &res)
+	return res, err
+}
+
+func (c *HttpMetadataClient) DeviceProfileByName(ctx context.Context, name string) (res responses.DeviceProfileResponse, err errors.EdgeX) {
+	requestPath := path.Join(common.ApiDeviceProfileRoute, common.Name, url.QueryEscape(name))
+	err = c.request(ctx, requestPath, nil, &res)
+	return res, err
+}
+
+func (c *HttpMetadataClient) request(ctx context.Context, requestPath string, requestParams url.Values, res interface{}) errors.EdgeX {
+	return requestJSON(ctx, "core-metadata", c.baseUrl, http.MethodGet, requestPath, requestParams, nil, nil, res)
+}
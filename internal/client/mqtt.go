@@ -0,0 +1,226 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pahoMqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/edgexfoundry/go-mod-messaging/v2/pkg/types"
+	"github.com/google/uuid"
+)
+
+const (
+	usernameOptionKey       = "Username"
+	passwordOptionKey       = "Password"
+	keepAliveOptionKey      = "KeepAlive"
+	connectTimeoutOptionKey = "ConnectTimeout"
+	autoReconnectOptionKey  = "AutoReconnect"
+	skipCertVerifyOptionKey = "SkipCertVerify"
+	certFileOptionKey       = "CertFile"
+	keyFileOptionKey        = "KeyFile"
+	certPEMBlockOptionKey   = "CertPEMBlock"
+	keyPEMBlockOptionKey    = "KeyPEMBlock"
+
+	defaultConnectTimeout = 5 * time.Second
+)
+
+// tlsSchemes are the schemes of the broker url which use TLS
+var tlsSchemes = []string{"tcps", "ssl", "tls"}
+
+// MqttMessageBusClient publishes messages to the MQTT message bus with the QoS and retained flag of each message.
+// The MQTT client of the EdgeX message bus publishes with the QoS and retained flag of its will message,
+// so the messages are published through paho, in the message envelope of the EdgeX message bus.
+type MqttMessageBusClient struct {
+	config types.MessageBusConfig
+	client pahoMqtt.Client
+	mutex  sync.Mutex
+}
+
+func NewMqttMessageBusClient(config types.MessageBusConfig) MessageBusClient {
+	return &MqttMessageBusClient{config: config}
+}
+
+func (c *MqttMessageBusClient) Publish(ctx context.Context, topic string, payload []byte, qos int, retained bool) error {
+	mqttClient, err := c.mqttClient()
+	if err != nil {
+		return err
+	}
+
+	envelope := types.NewMessageEnvelope(payload, ctx)
+	envelope.ContentType = ContentTypeJSON
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("encode message envelope error: %s", err.Error())
+	}
+
+	token := mqttClient.Publish(topic, byte(qos), retained, data)
+	select {
+	case <-token.Done():
+	case <-ctx.Done():
+		return fmt.Errorf("publish message error: %s", ctx.Err().Error())
+	}
+	if err = token.Error(); err != nil {
+		return fmt.Errorf("publish message error: %s", err.Error())
+	}
+	return nil
+}
+
+func (c *MqttMessageBusClient) Disconnect() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.client != nil {
+		c.client.Disconnect(0)
+		c.client = nil
+	}
+	return nil
+}
+
+// mqttClient returns the connection to the broker. The connection is made without holding the lock,
+// so an unreachable broker never blocks the disconnection
+func (c *MqttMessageBusClient) mqttClient() (pahoMqtt.Client, error) {
+	c.mutex.Lock()
+	mqttClient := c.client
+	c.mutex.Unlock()
+	if mqttClient != nil {
+		return mqttClient, nil
+	}
+
+	options, err := mqttClientOptions(c.config)
+	if err != nil {
+		return nil, fmt.Errorf("create message bus client error: %s", err.Error())
+	}
+	mqttClient = pahoMqtt.NewClient(options)
+	token := mqttClient.Connect()
+	if !token.WaitTimeout(options.ConnectTimeout) {
+		mqttClient.Disconnect(0)
+		return nil, fmt.Errorf("connect message bus error: no answer from the broker after %s", options.ConnectTimeout)
+	}
+	if err = token.Error(); err != nil {
+		return nil, fmt.Errorf("connect message bus error: %s", err.Error())
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// another publish connected meanwhile
+	if c.client != nil {
+		mqttClient.Disconnect(0)
+		return c.client, nil
+	}
+	c.client = mqttClient
+	return mqttClient, nil
+}
+
+// mqttClientOptions returns the paho options from the options of the EdgeX message bus
+func mqttClientOptions(config types.MessageBusConfig) (*pahoMqtt.ClientOptions, error) {
+	if config.PublishHost.IsHostInfoEmpty() {
+		return nil, fmt.Errorf("publish host of the message bus not configured")
+	}
+	brokerUrl := config.PublishHost.GetHostURL()
+
+	optional := config.Optional
+	clientId := optional[clientIdOptionKey]
+	if clientId == "" {
+		clientId = uuid.New().String()
+	}
+	keepAlive, err := intOption(optional, keepAliveOptionKey, 0)
+	if err != nil {
+		return nil, err
+	}
+	connectTimeout, err := intOption(optional, connectTimeoutOptionKey, int(defaultConnectTimeout/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	autoReconnect, err := boolOption(optional, autoReconnectOptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	options := pahoMqtt.NewClientOptions()
+	options.AddBroker(brokerUrl)
+	options.SetClientID(clientId)
+	options.SetUsername(optional[usernameOptionKey])
+	options.SetPassword(optional[passwordOptionKey])
+	options.SetKeepAlive(time.Duration(keepAlive) * time.Second)
+	options.SetConnectTimeout(time.Duration(connectTimeout) * time.Second)
+	options.SetAutoReconnect(autoReconnect)
+
+	tlsConfig, err := mqttTlsConfig(config.PublishHost.Protocol, optional)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		options.SetTLSConfig(tlsConfig)
+	}
+	return options, nil
+}
+
+// mqttTlsConfig returns the client certificate of a TLS broker, it returns nil if no certificate is configured
+func mqttTlsConfig(protocol string, optional map[string]string) (*tls.Config, error) {
+	certPEMBlock, keyPEMBlock := optional[certPEMBlockOptionKey], optional[keyPEMBlockOptionKey]
+	certFile, keyFile := optional[certFileOptionKey], optional[keyFileOptionKey]
+	if certPEMBlock == "" && keyPEMBlock == "" && certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+
+	isTls := false
+	for _, scheme := range tlsSchemes {
+		if strings.EqualFold(protocol, scheme) {
+			isTls = true
+		}
+	}
+	if !isTls {
+		return nil, nil
+	}
+
+	var cert tls.Certificate
+	var err error
+	if certPEMBlock != "" && keyPEMBlock != "" {
+		cert, err = tls.X509KeyPair([]byte(certPEMBlock), []byte(keyPEMBlock))
+	} else {
+		cert, err = tls.LoadX509KeyPair(certFile, keyFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load client certificate error: %s", err.Error())
+	}
+
+	skipCertVerify, err := boolOption(optional, skipCertVerifyOptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		InsecureSkipVerify: skipCertVerify,
+		Certificates:       []tls.Certificate{cert},
+	}, nil
+}
+
+func intOption(optional map[string]string, key string, defaultValue int) (int, error) {
+	value, ok := optional[key]
+	if !ok || value == "" {
+		return defaultValue, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("message bus option %s '%s' is not an integer", key, value)
+	}
+	return i, nil
+}
+
+func boolOption(optional map[string]string, key string) (bool, error) {
+	value, ok := optional[key]
+	if !ok || value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("message bus option %s '%s' is not a boolean", key, value)
+	}
+	return b, nil
+}
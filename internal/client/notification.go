@@ -0,0 +1,35 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	dtoCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+)
+
+// NotificationClient sends the notifications to support-notifications, the requests are cancelled with the context
+type NotificationClient interface {
+	SendNotifications(ctx context.Context, reqs []requests.AddNotificationRequest) errors.EdgeX
+}
+
+type HttpNotificationClient struct {
+	baseUrl string
+}
+
+func NewHttpNotificationClient(baseUrl string) NotificationClient {
+	return &HttpNotificationClient{
+		baseUrl: baseUrl,
+	}
+}
+
+func (c *HttpNotificationClient) SendNotifications(ctx context.Context, reqs []requests.AddNotificationRequest) errors.EdgeX {
+	var res []dtoCommon.BaseWithIdResponse
+	err := requestJSON(ctx, "support-notifications", c.baseUrl, http.MethodPost, common.ApiNotificationRoute, nil, nil, reqs, &res)
+	if err != nil {
+		return err
+	}
+	return batchError("support-notifications", withIdItems(res))
+}
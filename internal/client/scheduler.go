@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	dtoCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/responses"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+)
+
+// SchedulerClient manages the intervals and the interval actions of support-scheduler, the requests are
+// cancelled with the context. The batch requests fail if one of their items fails
+type SchedulerClient interface {
+	AddIntervals(ctx context.Context, reqs []requests.AddIntervalRequest) errors.EdgeX
+	UpdateIntervals(ctx context.Context, reqs []requests.UpdateIntervalRequest) errors.EdgeX
+	AllIntervals(ctx context.Context, offset int, limit int) (responses.MultiIntervalsResponse, errors.EdgeX)
+	IntervalByName(ctx context.Context, name string) (responses.IntervalResponse, errors.EdgeX)
+	DeleteIntervalByName(ctx context.Context, name string) errors.EdgeX
+	AddIntervalActions(ctx context.Context, reqs []requests.AddIntervalActionRequest) errors.EdgeX
+	UpdateIntervalActions(ctx context.Context, reqs []requests.UpdateIntervalActionRequest) errors.EdgeX
+	AllIntervalActions(ctx context.Context, offset int, limit int) (responses.MultiIntervalActionsResponse, errors.EdgeX)
+	IntervalActionByName(ctx context.Context, name string) (responses.IntervalActionResponse, errors.EdgeX)
+	DeleteIntervalActionByName(ctx context.Context, name string) errors.EdgeX
+}
+
+type HttpSchedulerClient struct {
+	baseUrl string
+}
+
+func NewHttpSchedulerClient(baseUrl string) SchedulerClient {
+	return &HttpSchedulerClient{
+		baseUrl: baseUrl,
+	}
+}
+
+func (c *HttpSchedulerClient) AddIntervals(ctx context.Context, reqs []requests.AddIntervalRequest) errors.EdgeX {
+	var res []dtoCommon.BaseWithIdResponse
+	if err := c.request(ctx, http.MethodPost, common.ApiIntervalRoute, nil, reqs, &res); err != nil {
+		return err
+	}
+	return batchError("support-scheduler", withIdItems(res))
+}
+
+func (c *HttpSchedulerClient) UpdateIntervals(ctx context.Context, reqs []requests.UpdateIntervalRequest) errors.EdgeX {
+	var res []dtoCommon.BaseResponse
+	if err := c.request(ctx, http.MethodPatch, common.ApiIntervalRoute, nil, reqs, &res); err != nil {
+		return err
+	}
+	return batchError("support-scheduler", res)
+}
+
+func (c *HttpSchedulerClient) AllIntervals(ctx context.Context, offset int, limit int) (res responses.MultiIntervalsResponse, err errors.EdgeX) {
+	err = c.request(ctx, http.MethodGet, common.ApiAllIntervalRoute, pageParams(offset, limit), nil, &res)
+	return res, err
+}
+
+func (c *HttpSchedulerClient) IntervalByName(ctx context.Context, name string) (res responses.IntervalResponse, err errors.EdgeX) {
+	requestPath := path.Join(common.ApiIntervalRoute, common.Name, url.QueryEscape(name))
+	err = c.request(ctx, http.MethodGet, requestPath, nil, nil, &res)
+	return res, err
+}
+
+func (c *HttpSchedulerClient) DeleteIntervalByName(ctx context.Context, name string) errors.EdgeX {
+	requestPath := path.Join(common.ApiIntervalRoute, common.Name, url.QueryEscape(name))
+	return c.request(ctx, http.MethodDelete, requestPath, nil, nil, nil)
+}
+
+func (c *HttpSchedulerClient) AddIntervalActions(ctx context.Context, reqs []requests.AddIntervalActionRequest) errors.EdgeX {
+	var res []dtoCommon.BaseWithIdResponse
+	if err := c.request(ctx, http.MethodPost, common.ApiIntervalActionRoute, nil, reqs, &res); err != nil {
+		return err
+	}
+	return batchError("support-scheduler", withIdItems(res))
+}
+
+func (c *HttpSchedulerClient) UpdateIntervalActions(ctx context.Context, reqs []requests.UpdateIntervalActionRequest) errors.EdgeX {
+	var res []dtoCommon.BaseResponse
+	if err := c.request(ctx, http.MethodPatch, common.ApiIntervalActionRoute, nil, reqs, &res); err != nil {
+		return err
+	}
+	return batchError("support-scheduler", res)
+}
+
+func (c *HttpSchedulerClient) AllIntervalActions(ctx context.Context, offset int, limit int) (res responses.MultiIntervalActionsResponse, err errors.EdgeX) {
+	err = c.request(ctx, http.MethodGet, common.ApiAllIntervalActionRoute, pageParams(offset, limit), nil, &res)
+	return res, err
+}
+
+func (c *HttpSchedulerClient) IntervalActionByName(ctx context.Context, name string) (res responses.IntervalActionResponse, err errors.EdgeX) {
+	requestPath := path.Join(common.ApiIntervalActionRoute, common.Name, url.QueryEscape(name))
+	err = c.request(ctx, http.MethodGet, requestPath, nil, nil, &res)
+	return res, err
+}
+
+func (c *HttpSchedulerClient) DeleteIntervalActionByName(ctx context.Context, name string) errors.EdgeX {
+	requestPath := path.Join(common.ApiIntervalActionRoute, common.Name, url.QueryEscape(name))
+	return c.request(ctx, http.MethodDelete, requestPath, nil, nil, nil)
+}
+
+func (c *HttpSchedulerClient) request(ctx context.Context, method string, requestPath string, requestParams url.Values, data interface{}, res interface{}) errors.EdgeX {
+	return requestJSON(ctx, "support-scheduler", c.baseUrl, method, requestPath, requestParams, nil, data, res)
+}
+
+func pageParams(offset int, limit int) url.Values {
+	requestParams := url.Values{}
+	requestParams.Set(common.Offset, strconv.Itoa(offset))
+	requestParams.Set(common.Limit, strconv.Itoa(limit))
+	return requestParams
+}
+
+func withIdItems(res []dtoCommon.BaseWithIdResponse) []dtoCommon.BaseResponse {
+	items := make([]dtoCommon.BaseResponse, len(res))
+	for i, r := range res {
+		items[i] = r.BaseResponse
+	}
+	return items
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	dtoCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+)
+
+func TestHttpSchedulerClientAddIntervals(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		items    []dtoCommon.BaseWithIdResponse
+		wantKind errors.ErrKind
+	}{
+		{"created", http.StatusMultiStatus, []dtoCommon.BaseWithIdResponse{{BaseResponse: dtoCommon.BaseResponse{StatusCode: http.StatusCreated}}}, ""},
+		{"failed item", http.StatusMultiStatus, []dtoCommon.BaseWithIdResponse{
+			{BaseResponse: dtoCommon.BaseResponse{StatusCode: http.StatusCreated}},
+			{BaseResponse: dtoCommon.BaseResponse{StatusCode: http.StatusConflict, Message: "interval exists"}},
+		}, errors.KindStatusConflict},
+		{"failed request", http.StatusServiceUnavailable, nil, errors.KindServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != common.ApiIntervalRoute {
+					t.Errorf("request %s %s, want POST %s", r.Method, r.URL.Path, common.ApiIntervalRoute)
+				}
+				w.WriteHeader(tt.status)
+				_ = json.NewEncoder(w).Encode(tt.items)
+			}))
+			defer server.Close()
+
+			err := NewHttpSchedulerClient(server.URL).AddIntervals(context.Background(), []requests.AddIntervalRequest{{}})
+			if tt.wantKind == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || errors.Kind(err) != tt.wantKind {
+				t.Fatalf("error = %v, want kind %s", err, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestHttpSchedulerClientCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := NewHttpSchedulerClient(server.URL).DeleteIntervalByName(ctx, "night-mode")
+	if err == nil || errors.Kind(err) != errors.KindServiceUnavailable {
+		t.Fatalf("error = %v, want kind %s", err, errors.KindServiceUnavailable)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("request returned after %s, want it cancelled with the context", elapsed)
+	}
+}
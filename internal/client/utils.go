@@ -2,9 +2,16 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"time"
+
+	dtoCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
 )
 
 const (
@@ -12,35 +19,100 @@ const (
 	ContentTypeJSON = "application/json"
 )
 
-// SendRequest will make a request with raw data to the specified URL.
+// SendRequest will make a request with raw data to the specified URL, the request is cancelled after the timeout.
 // It returns the body as a byte array if successful and an error otherwise.
-func SendRequest(baseUrl string, api string, method string, data []byte) (response []byte, err error) {
+func SendRequest(baseUrl string, api string, method string, data []byte, timeout time.Duration) (response []byte, err error) {
 	url := baseUrl + "/" + api
-	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	statusCode, bodyBytes, err := SendRawRequest(context.Background(), url, method, nil, data, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode <= http.StatusMultiStatus {
+		return bodyBytes, nil
+	}
+	return nil, fmt.Errorf("request failed, status code: %d, err: %s", statusCode, string(bodyBytes))
+}
+
+// SendRawRequest will make a request with raw data and extra headers to the specified URL.
+// It returns the status code and the body of the response whatever the status code is,
+// the timeout is ignored if it is not positive.
+func SendRawRequest(ctx context.Context, url string, method string, headers map[string]string, data []byte, timeout time.Duration) (statusCode int, response []byte, err error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create a http request %s", err.Error())
+		return 0, nil, fmt.Errorf("failed to create a http request %s", err.Error())
 	}
 	req.Header.Set(ContentType, ContentTypeJSON)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send a http request %s", err.Error())
+		return 0, nil, fmt.Errorf("failed to send a http request %s", err.Error())
 	}
 
 	if resp == nil {
-		return nil, fmt.Errorf("the response should not be a nil")
+		return 0, nil, fmt.Errorf("the response should not be a nil")
 	}
 
 	defer resp.Body.Close()
 
 	bodyBytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return resp.StatusCode, nil, err
 	}
 
-	if resp.StatusCode <= http.StatusMultiStatus {
-		return bodyBytes, nil
+	return resp.StatusCode, bodyBytes, nil
+}
+
+// requestJSON sends the data encoded in JSON to the service and decodes the response into res, the request
+// is cancelled with the context. A status code other than 2xx is returned as an error of the matching kind
+func requestJSON(ctx context.Context, service string, baseUrl string, method string, requestPath string, requestParams url.Values,
+	headers map[string]string, data interface{}, res interface{}) errors.EdgeX {
+	requestUrl := baseUrl + requestPath
+	if len(requestParams) > 0 {
+		requestUrl = requestUrl + "?" + requestParams.Encode()
+	}
+
+	var body []byte
+	if data != nil {
+		var err error
+		if body, err = json.Marshal(data); err != nil {
+			return errors.NewCommonEdgeX(errors.KindContractInvalid, "failed to encode the request", err)
+		}
+	}
+
+	statusCode, response, err := SendRawRequest(ctx, requestUrl, method, headers, body, 0)
+	if err != nil {
+		return errors.NewCommonEdgeX(errors.KindServiceUnavailable, fmt.Sprintf("%s %s", service, baseUrl), err)
+	}
+	if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+		return errors.NewCommonEdgeX(errors.KindMapping(statusCode), fmt.Sprintf("%s %s, status code: %d, response: %s", service, baseUrl, statusCode, string(response)), nil)
+	}
+	if len(response) == 0 || res == nil {
+		return nil
+	}
+	if err = json.Unmarshal(response, res); err != nil {
+		return errors.NewCommonEdgeX(errors.KindServerError, "failed to decode the response", err)
+	}
+	return nil
+}
+
+// batchError returns the first failed item of the response of a batch request, the status code of
+// the batch request itself is 207 Multi-Status whatever the status codes of the items are
+func batchError(service string, items []dtoCommon.BaseResponse) errors.EdgeX {
+	for _, item := range items {
+		if item.StatusCode < http.StatusOK || item.StatusCode >= http.StatusMultipleChoices {
+			return errors.NewCommonEdgeX(errors.KindMapping(item.StatusCode), fmt.Sprintf("%s, status code: %d, message: %s", service, item.StatusCode, item.Message), nil)
+		}
 	}
-	return nil, fmt.Errorf("request failed, status code: %d, err: %s", resp.StatusCode, string(bodyBytes))
+	return nil
 }
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type WebhookClient interface {
+	Call(ctx context.Context, method string, url string, headers map[string]string, body []byte, timeout time.Duration, expectedStatusCodes []int) ([]byte, error)
+}
+
+type HttpWebhookClient struct {
+	defaultTimeout time.Duration
+}
+
+func NewHttpWebhookClient(defaultTimeout time.Duration) WebhookClient {
+	return &HttpWebhookClient{
+		defaultTimeout: defaultTimeout,
+	}
+}
+
+// Call sends the body to the url and checks the status code of the response.
+// Any 2xx status code is accepted when expectedStatusCodes is empty.
+func (c *HttpWebhookClient) Call(ctx context.Context, method string, url string, headers map[string]string, body []byte, timeout time.Duration, expectedStatusCodes []int) ([]byte, error) {
+	if method == "" {
+		method = http.MethodPost
+	}
+	if timeout <= 0 {
+		timeout = c.defaultTimeout
+	}
+
+	statusCode, response, err := SendRawRequest(ctx, url, method, headers, body, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isExpectedStatusCode(statusCode, expectedStatusCodes) {
+		return nil, fmt.Errorf("unexpected status code: %d, response: %s", statusCode, string(response))
+	}
+	return response, nil
+}
+
+func isExpectedStatusCode(statusCode int, expectedStatusCodes []int) bool {
+	if len(expectedStatusCodes) == 0 {
+		return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+	}
+
+	for _, code := range expectedStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
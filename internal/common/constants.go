@@ -1,11 +1,26 @@
 package common
 
-import contractsCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+import (
+	"time"
+
+	contractsCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+)
 
 const (
 	ActionsProperty      = "actions"
 	NotifyEnableProperty = "notify"
 	ConditionsProperty   = "conditions"
+	NotificationProperty = "notification"
+	ExecutionProperty    = "execution"
+
+	NotificationContentProperty     = "content"
+	NotificationCategoryProperty    = "category"
+	NotificationSeverityProperty    = "severity"
+	NotificationLabelsProperty      = "labels"
+	NotificationDescriptionProperty = "description"
+
+	ExecutionTimeoutProperty  = "timeout"
+	ExecutionPriorityProperty = "priority"
 
 	ScheduleRuleType  = "schedule"
 	ThresholdRuleType = "threshold"
@@ -13,15 +28,48 @@ const (
 
 // Constants related to defined routes in the v2 service APIs
 const (
-	ApiRuleRoute            = contractsCommon.ApiBase + "/" + Rule                                          // POST
-	ApiAllRuleRoute         = ApiRuleRoute + "/" + contractsCommon.All                                      // GET
-	ApiRuleByNameRoute      = ApiRuleRoute + "/" + contractsCommon.Name + "/{" + contractsCommon.Name + "}" // GET, PUT
-	ApiRuleTriggerByIdRoute = ApiRuleRoute + "/" + contractsCommon.Id + "/{" + contractsCommon.Id + "}"     // POST
+	ApiRuleRoute              = contractsCommon.ApiBase + "/" + Rule                                          // POST
+	ApiAllRuleRoute           = ApiRuleRoute + "/" + contractsCommon.All                                      // GET
+	ApiRuleByNameRoute        = ApiRuleRoute + "/" + contractsCommon.Name + "/{" + contractsCommon.Name + "}" // GET, PUT
+	ApiRuleTriggerByIdRoute   = ApiRuleRoute + "/" + contractsCommon.Id + "/{" + contractsCommon.Id + "}"     // POST
+	ApiRuleExplainByNameRoute = ApiRuleByNameRoute + "/" + Explain                                            // GET
+	ApiRuleSimulateRoute      = ApiRuleRoute + "/" + Simulate                                                 // POST
+	ApiRuleExecuteByNameRoute = ApiRuleByNameRoute + "/" + Execute                                            // POST
+
+	ApiTimerRoute     = contractsCommon.ApiBase + "/" + Timer
+	ApiAllTimerRoute  = ApiTimerRoute + "/" + contractsCommon.All                                  // GET
+	ApiTimerByIdRoute = ApiTimerRoute + "/" + contractsCommon.Id + "/{" + contractsCommon.Id + "}" // DELETE
+
+	ApiSnapshotRoute       = contractsCommon.ApiBase + "/" + Snapshot
+	ApiAllSnapshotRoute    = ApiSnapshotRoute + "/" + contractsCommon.All                                      // GET
+	ApiSnapshotByNameRoute = ApiSnapshotRoute + "/" + contractsCommon.Name + "/{" + contractsCommon.Name + "}" // GET, DELETE
+
+	ApiTriggerQueueRoute = contractsCommon.ApiBase + "/" + Queue // GET
+
+	ApiPauseRoute  = contractsCommon.ApiBase + "/" + Pause  // GET, POST
+	ApiResumeRoute = contractsCommon.ApiBase + "/" + Resume // POST
+
+	ApiHistoryRoute    = contractsCommon.ApiBase + "/" + History
+	ApiAllHistoryRoute = ApiHistoryRoute + "/" + contractsCommon.All // GET
 )
 
 // Constants related to defined url path names and parameters in the v2 service APIs
 const (
-	Rule = "rule"
+	Rule     = "rule"
+	Timer    = "timer"
+	Snapshot = "snapshot"
+	History  = "history"
+	Explain  = "explain"
+	Simulate = "simulate"
+	Execute  = "execute"
+	Queue    = "queue"
+	Pause    = "pause"
+	Resume   = "resume"
+
+	RuleNameParam = "ruleName"
+	StartParam    = "start"
+	EndParam      = "end"
+	OutcomeParam  = "outcome"
 )
 
 // Constants related to defined profiles and device service
@@ -29,9 +77,28 @@ const (
 	ManualScenarioProfile = "ManualScenario"
 	AutoScenarioProfile   = "AutoScenario"
 
+	ContentsProperty = "Contents"
+
 	DeviceServiceName = "scenario"
 )
 
+// Constants related to defined default notification
+const (
+	DefaultNotificationCategory = "trigger-event"
+	DefaultNotificationContent  = "auto-scenario '{{.RuleName}}' triggered"
+	NotificationSender          = "scenario-service"
+	StormNotificationCategory   = "rule-storm"
+)
+
+// Constants related to defined names in the local store
+const (
+	TimersStoreName    = "timers"
+	SnapshotsStoreName = "snapshots"
+	HistoryStoreName   = "history"
+	StatesStoreName    = "states"
+	PauseStoreName     = "pause"
+)
+
 // Constants related to defined logic type
 const (
 	AndLogic = "and"
@@ -41,4 +108,29 @@ const (
 const (
 	CharacterGenName = "_"
 	DefaultLimit     = 1000
+
+	DefaultWebhookTimeout = 10 * time.Second
+	DefaultWaitInterval   = time.Second
+	DefaultActionTimeout  = 30 * time.Second
+	DefaultRuleTimeout    = 5 * time.Minute
+	DefaultRequestTimeout = 10 * time.Second
+	MaxScenarioDepth      = 5
+	DefaultStoragePath    = "./data"
+
+	// DefaultPersistDelay is the delay of the saves of the frequently changing state in the local store
+	DefaultPersistDelay = time.Second
+
+	DefaultHistoryMaxRecords = 1000
+	DefaultHistoryMaxAge     = 7 * 24 * time.Hour
+
+	DefaultTriggerWorkers   = 4
+	DefaultTriggerQueueSize = 100
+
+	DefaultStormMaxFiresPerMinute = 60
+)
+
+// Constants related to the overflow policies of the trigger queue
+const (
+	DropOldestOverflow = "DropOldest"
+	RejectOverflow     = "Reject"
 )
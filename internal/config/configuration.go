@@ -19,6 +19,11 @@ package config
 import (
 	"errors"
 	"fmt"
+	"time"
+
+	bootstrapConfig "github.com/edgexfoundry/go-mod-bootstrap/v2/config"
+
+	"github.com/rddigital/device-scenario/internal/common"
 )
 
 // ClientInfo provides the host and port of another service in the eco-system.
@@ -36,7 +41,62 @@ func (c ClientInfo) Url() string {
 	return url
 }
 
+// StorageInfo provides the location of the local storage of the service.
+type StorageInfo struct {
+	// Path is the directory where the local state of the service is saved
+	Path string
+}
+
+// ExecutionInfo provides the deadlines of the executions of the rules, the values are durations like "30s".
+type ExecutionInfo struct {
+	// ActionTimeout is the default deadline of an action, the actions running other actions have no default deadline
+	ActionTimeout string
+	// RuleTimeout is the default deadline of all the actions of a rule
+	RuleTimeout string
+	// RequestTimeout is the deadline of the requests to the other services outside the execution of actions
+	RequestTimeout string
+}
+
+// HistoryInfo provides the retention of the execution records.
+type HistoryInfo struct {
+	// MaxRecords is the maximum number of records, the oldest records are removed first
+	MaxRecords int
+	// MaxAge is the maximum age of a record, e.g. "168h"
+	MaxAge string
+}
+
+// TriggerQueueInfo provides the queue of the callbacks of the conditions of the rules.
+type TriggerQueueInfo struct {
+	// Workers is the number of callbacks run at the same time, the callbacks of a rule are always run one at a time
+	Workers int
+	// QueueSize is the maximum number of callbacks waiting for a worker
+	QueueSize int
+	// Overflow is what happens when the queue is full: "DropOldest" drops the oldest queued callback,
+	// "Reject" answers the new callback with 429 Too Many Requests
+	Overflow string
+}
+
+// StormInfo provides the storm breaker which locks the rules firing too often, e.g. in an automation loop.
+type StormInfo struct {
+	// MaxFiresPerMinute is the maximum number of times a rule fires in a minute before it is locked
+	MaxFiresPerMinute int
+}
+
+// RemoteCommandInfo provides the core-command of another EdgeX instance.
+type RemoteCommandInfo struct {
+	// BaseUrl is the url of core-command, e.g. https://site-b:8443/core-command
+	BaseUrl string
+	// AuthHeaderName and AuthHeaderValue are sent with each request if they are set,
+	// e.g. the Authorization header of the API gateway
+	AuthHeaderName  string
+	AuthHeaderValue string
+}
+
 type ServiceConfig struct {
+	// MessageQueue is the message bus used by the publish actions
+	MessageQueue bootstrapConfig.MessageBusInfo
+	// Clients are the EdgeX services used by the device service, the rules resolve their devices in core-metadata
+	Clients             map[string]bootstrapConfig.ClientInfo
 	ServiceCustomConfig ServiceCustomConfig
 }
 
@@ -46,6 +106,13 @@ type ServiceCustomConfig struct {
 	NotificationClientInfo ClientInfo
 	SchedulerClientInfo    ClientInfo
 	RuleEngineClientInfo   ClientInfo
+	StorageInfo            StorageInfo
+	ExecutionInfo          ExecutionInfo
+	HistoryInfo            HistoryInfo
+	TriggerQueueInfo       TriggerQueueInfo
+	StormInfo              StormInfo
+	// RemoteCommandClients are the core-command of other EdgeX instances, the key is the name used by the actions
+	RemoteCommandClients map[string]RemoteCommandInfo
 }
 
 // UpdateFromRaw updates the service's full configuration from raw data received from
@@ -99,5 +166,45 @@ func (scc *ServiceCustomConfig) Validate() error {
 		return errors.New("port setting for Rule Engine client not configured")
 	}
 
+	for _, timeout := range []string{scc.ExecutionInfo.ActionTimeout, scc.ExecutionInfo.RuleTimeout, scc.ExecutionInfo.RequestTimeout} {
+		if timeout == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(timeout); err != nil {
+			return fmt.Errorf("execution timeout setting '%s' is not a duration: %s", timeout, err.Error())
+		}
+	}
+
+	if scc.HistoryInfo.MaxRecords < 0 {
+		return errors.New("history max records setting must not be negative")
+	}
+	if scc.HistoryInfo.MaxAge != "" {
+		if _, err := time.ParseDuration(scc.HistoryInfo.MaxAge); err != nil {
+			return fmt.Errorf("history max age setting '%s' is not a duration: %s", scc.HistoryInfo.MaxAge, err.Error())
+		}
+	}
+
+	if scc.TriggerQueueInfo.Workers < 0 {
+		return errors.New("trigger queue workers setting must not be negative")
+	}
+	if scc.TriggerQueueInfo.QueueSize < 0 {
+		return errors.New("trigger queue size setting must not be negative")
+	}
+	switch scc.TriggerQueueInfo.Overflow {
+	case "", common.DropOldestOverflow, common.RejectOverflow:
+	default:
+		return fmt.Errorf("trigger queue overflow setting '%s' must be 'DropOldest' or 'Reject'", scc.TriggerQueueInfo.Overflow)
+	}
+
+	if scc.StormInfo.MaxFiresPerMinute < 0 {
+		return errors.New("storm max fires per minute setting must not be negative")
+	}
+
+	for name, remote := range scc.RemoteCommandClients {
+		if len(remote.BaseUrl) == 0 {
+			return fmt.Errorf("base url setting for remote Core Command client '%s' not configured", name)
+		}
+	}
+
 	return nil
 }
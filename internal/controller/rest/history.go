@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	contractsCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+
+	"github.com/rddigital/device-scenario/internal/application"
+	"github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+func GetAllHistoryHander(w http.ResponseWriter, r *http.Request) {
+	filter, edgexErr := parseHistoryFilter(r)
+	if edgexErr != nil {
+		SendEdgexError(w, r, edgexErr)
+		return
+	}
+
+	records, totalCount := application.QueryExecutionRecords(filter)
+	correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+	response := models.NewMultiExecutionRecordsResponse(correlationID, "", http.StatusOK, totalCount, records)
+	SendResponse(w, r, response, http.StatusOK)
+}
+
+// parseHistoryFilter reads the filter from the query parameters, start and end are in nanoseconds
+func parseHistoryFilter(r *http.Request) (models.ExecutionRecordFilter, errors.EdgeX) {
+	query := r.URL.Query()
+	filter := models.ExecutionRecordFilter{
+		RuleName: query.Get(common.RuleNameParam),
+		Outcome:  query.Get(common.OutcomeParam),
+		Offset:   contractsCommon.DefaultOffset,
+		Limit:    contractsCommon.DefaultLimit,
+	}
+
+	if filter.Outcome != "" && filter.Outcome != models.ExecutionSucceeded && filter.Outcome != models.ExecutionFailed {
+		return filter, errors.NewCommonEdgeX(errors.KindContractInvalid, fmt.Sprintf("invalid outcome '%s'", filter.Outcome), nil)
+	}
+
+	var err error
+	for name, value := range map[string]*int64{common.StartParam: &filter.Start, common.EndParam: &filter.End} {
+		if str := query.Get(name); str != "" {
+			if *value, err = strconv.ParseInt(str, 10, 64); err != nil {
+				return filter, errors.NewCommonEdgeX(errors.KindContractInvalid, fmt.Sprintf("invalid %s '%s'", name, str), err)
+			}
+		}
+	}
+	for name, value := range map[string]*int{contractsCommon.Offset: &filter.Offset, contractsCommon.Limit: &filter.Limit} {
+		if str := query.Get(name); str != "" {
+			if *value, err = strconv.Atoi(str); err != nil {
+				return filter, errors.NewCommonEdgeX(errors.KindContractInvalid, fmt.Sprintf("invalid %s '%s'", name, str), err)
+			}
+		}
+	}
+	if filter.Offset < 0 {
+		return filter, errors.NewCommonEdgeX(errors.KindContractInvalid, "offset must not be negative", nil)
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	contractsCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/errors"
+
+	"github.com/rddigital/device-scenario/internal/application"
+	"github.com/rddigital/device-scenario/internal/common"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+func GetPauseHander(w http.ResponseWriter, r *http.Request) {
+	correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+	response := models.NewPauseStateResponse(correlationID, "", http.StatusOK, application.GetPauseState())
+	SendResponse(w, r, response, http.StatusOK)
+}
+
+func PauseHander(w http.ResponseWriter, r *http.Request) {
+	if r.Body != nil {
+		defer func() { _ = r.Body.Close() }()
+	}
+
+	// the options are optional, an empty body pauses all automations until the resume API is called
+	var pauseRequest models.PauseRequest
+	if r.Body != nil {
+		err := json.NewDecoder(r.Body).Decode(&pauseRequest)
+		if err != nil && err != io.EOF {
+			edgexErr := errors.NewCommonEdgeX(errors.KindServerError, "failed to decode JSON", err)
+			SendEdgexError(w, r, edgexErr)
+			return
+		}
+	}
+
+	err := common.Validate(pauseRequest)
+	if err != nil {
+		edgexErr := errors.NewCommonEdgeX(errors.KindContractInvalid, "failed to validation", err)
+		SendEdgexError(w, r, edgexErr)
+		return
+	}
+
+	state, edgexErr := application.PauseAutomations(pauseRequest)
+	if edgexErr == nil {
+		correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+		response := models.NewPauseStateResponse(correlationID, "", http.StatusOK, state)
+		SendResponse(w, r, response, http.StatusOK)
+	} else {
+		SendEdgexError(w, r, edgexErr)
+	}
+}
+
+func ResumeHander(w http.ResponseWriter, r *http.Request) {
+	correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+	response := models.NewPauseStateResponse(correlationID, "", http.StatusOK, application.ResumeAutomations())
+	SendResponse(w, r, response, http.StatusOK)
+}
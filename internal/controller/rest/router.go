@@ -20,6 +20,24 @@ func InitRuleServer() {
 	ds.AddRoute(common.ApiRuleByNameRoute, UpdateRuleByNameHander, http.MethodPut)
 	ds.AddRoute(common.ApiRuleByNameRoute, DeleteRuleByNameHander, http.MethodDelete)
 	ds.AddRoute(common.ApiRuleTriggerByIdRoute, TriggerRuleByIdHander, http.MethodPost)
+	ds.AddRoute(common.ApiRuleExplainByNameRoute, ExplainRuleByNameHander, http.MethodGet)
+	ds.AddRoute(common.ApiRuleSimulateRoute, SimulateRuleHander, http.MethodPost)
+	ds.AddRoute(common.ApiRuleExecuteByNameRoute, ExecuteRuleByNameHander, http.MethodPost)
+
+	ds.AddRoute(common.ApiAllTimerRoute, GetAllTimerHander, http.MethodGet)
+	ds.AddRoute(common.ApiTimerByIdRoute, DeleteTimerByIdHander, http.MethodDelete)
+
+	ds.AddRoute(common.ApiAllSnapshotRoute, GetAllSnapshotHander, http.MethodGet)
+	ds.AddRoute(common.ApiSnapshotByNameRoute, GetSnapshotByNameHander, http.MethodGet)
+	ds.AddRoute(common.ApiSnapshotByNameRoute, DeleteSnapshotByNameHander, http.MethodDelete)
+
+	ds.AddRoute(common.ApiAllHistoryRoute, GetAllHistoryHander, http.MethodGet)
+
+	ds.AddRoute(common.ApiTriggerQueueRoute, GetTriggerQueueHander, http.MethodGet)
+
+	ds.AddRoute(common.ApiPauseRoute, GetPauseHander, http.MethodGet)
+	ds.AddRoute(common.ApiPauseRoute, PauseHander, http.MethodPost)
+	ds.AddRoute(common.ApiResumeRoute, ResumeHander, http.MethodPost)
 }
 
 // SendResponse puts together the response packet for the V2 API
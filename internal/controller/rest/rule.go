@@ -2,8 +2,8 @@ package rest
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
-	"strconv"
 
 	contractsCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
 	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
@@ -41,7 +41,7 @@ func AddRuleHander(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if enable, _ := strconv.ParseBool(addRuleRequest.Rule.NotifyEnable); (len(addRuleRequest.Rule.Actions) == 0) && (!enable) {
+	if (len(addRuleRequest.Rule.Actions) == 0) && (!addRuleRequest.Rule.NotificationEnabled()) {
 		edgexErr := errors.NewCommonEdgeX(errors.KindServerError, "no action rule", err)
 		SendEdgexError(w, r, edgexErr)
 		return
@@ -53,10 +53,10 @@ func AddRuleHander(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	edgexErr := application.AddRule(addRuleRequest.Rule)
+	warnings, edgexErr := application.AddRule(addRuleRequest.Rule)
 	if edgexErr == nil {
 		correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
-		response := commonDTO.NewBaseResponse(correlationID, "", http.StatusOK)
+		response := models.NewRuleWarningsResponse(correlationID, "", http.StatusOK, warnings)
 		SendResponse(w, r, response, http.StatusOK)
 	} else {
 		SendEdgexError(w, r, edgexErr)
@@ -85,6 +85,91 @@ func GetRuleByNameHander(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func ExplainRuleByNameHander(w http.ResponseWriter, r *http.Request) {
+	// URL parameters
+	vars := mux.Vars(r)
+	name := vars[contractsCommon.Name]
+
+	explanation, edgexErr := application.ExplainRuleByName(name)
+	if edgexErr == nil {
+		correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+		response := models.NewRuleExplanationResponse(correlationID, "", http.StatusOK, explanation)
+		SendResponse(w, r, response, http.StatusOK)
+	} else {
+		SendEdgexError(w, r, edgexErr)
+	}
+}
+
+func SimulateRuleHander(w http.ResponseWriter, r *http.Request) {
+	if r.Body != nil {
+		defer func() { _ = r.Body.Close() }()
+	}
+
+	var simulationRequest models.SimulationRequest
+	err := json.NewDecoder(r.Body).Decode(&simulationRequest)
+	if err != nil {
+		edgexErr := errors.NewCommonEdgeX(errors.KindServerError, "failed to decode JSON", err)
+		SendEdgexError(w, r, edgexErr)
+		return
+	}
+
+	err = common.Validate(simulationRequest)
+	if err != nil {
+		edgexErr := errors.NewCommonEdgeX(errors.KindContractInvalid, "failed to validation", err)
+		SendEdgexError(w, r, edgexErr)
+		return
+	}
+
+	simulation, edgexErr := application.SimulateRule(simulationRequest)
+	if edgexErr == nil {
+		correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+		response := models.NewSimulationResponse(correlationID, "", http.StatusOK, simulation)
+		SendResponse(w, r, response, http.StatusOK)
+	} else {
+		SendEdgexError(w, r, edgexErr)
+	}
+}
+
+func ExecuteRuleByNameHander(w http.ResponseWriter, r *http.Request) {
+	// URL parameters
+	vars := mux.Vars(r)
+	name := vars[contractsCommon.Name]
+
+	if r.Body != nil {
+		defer func() { _ = r.Body.Close() }()
+	}
+
+	// the options are optional, an empty body executes the actions of an unlocked rule
+	var executeRequest models.ExecuteRuleRequest
+	if r.Body != nil {
+		err := json.NewDecoder(r.Body).Decode(&executeRequest)
+		if err != nil && err != io.EOF {
+			edgexErr := errors.NewCommonEdgeX(errors.KindServerError, "failed to decode JSON", err)
+			SendEdgexError(w, r, edgexErr)
+			return
+		}
+	}
+
+	err := common.Validate(executeRequest)
+	if err != nil {
+		edgexErr := errors.NewCommonEdgeX(errors.KindContractInvalid, "failed to validation", err)
+		SendEdgexError(w, r, edgexErr)
+		return
+	}
+
+	correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+	execution, edgexErr := application.ExecuteRuleByName(name, executeRequest)
+	if edgexErr == nil {
+		response := models.NewRuleExecutionResponse(correlationID, "", http.StatusOK, execution)
+		SendResponse(w, r, response, http.StatusOK)
+	} else if errors.Kind(edgexErr) == errors.KindLimitExceeded {
+		response := commonDTO.NewBaseResponse(correlationID, edgexErr.Error(), http.StatusTooManyRequests)
+		SendResponse(w, r, response, http.StatusTooManyRequests)
+	} else {
+		SendEdgexError(w, r, edgexErr)
+	}
+}
+
 func UpdateRuleByNameHander(w http.ResponseWriter, r *http.Request) {
 	// URL parameters
 	vars := mux.Vars(r)
@@ -109,10 +194,10 @@ func UpdateRuleByNameHander(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	edgexErr := application.UpdateRuleByName(name, updateRuleRequest.Rule)
+	warnings, edgexErr := application.UpdateRuleByName(name, updateRuleRequest.Rule)
 	if edgexErr == nil {
 		correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
-		response := commonDTO.NewBaseResponse(correlationID, "", http.StatusOK)
+		response := models.NewRuleWarningsResponse(correlationID, "", http.StatusOK, warnings)
 		SendResponse(w, r, response, http.StatusOK)
 	} else {
 		SendEdgexError(w, r, edgexErr)
@@ -158,9 +243,24 @@ func TriggerRuleByIdHander(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	go application.TriggerRuleById(id, triggerContent)
-
 	correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+	edgexErr := application.EnqueueTrigger(id, triggerContent)
+	if edgexErr != nil {
+		if errors.Kind(edgexErr) == errors.KindLimitExceeded {
+			response := commonDTO.NewBaseResponse(correlationID, edgexErr.Error(), http.StatusTooManyRequests)
+			SendResponse(w, r, response, http.StatusTooManyRequests)
+			return
+		}
+		SendEdgexError(w, r, edgexErr)
+		return
+	}
+
 	response := commonDTO.NewBaseResponse(correlationID, "", http.StatusAccepted)
 	SendResponse(w, r, response, http.StatusAccepted)
 }
+
+func GetTriggerQueueHander(w http.ResponseWriter, r *http.Request) {
+	correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+	response := models.NewTriggerQueueStatusResponse(correlationID, "", http.StatusOK, application.GetTriggerQueueStatus())
+	SendResponse(w, r, response, http.StatusOK)
+}
@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"net/http"
+
+	contractsCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+	"github.com/gorilla/mux"
+
+	"github.com/rddigital/device-scenario/internal/application"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+func GetAllSnapshotHander(w http.ResponseWriter, r *http.Request) {
+	snapshotsResponse := application.GetAllSnapshots()
+	correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+	response := models.NewMultiSnapshotsResponse(correlationID, "", http.StatusOK, snapshotsResponse)
+	SendResponse(w, r, response, http.StatusOK)
+}
+
+func GetSnapshotByNameHander(w http.ResponseWriter, r *http.Request) {
+	// URL parameters
+	vars := mux.Vars(r)
+	name := vars[contractsCommon.Name]
+
+	snapshotResponse, edgexErr := application.GetSnapshotByName(name)
+	if edgexErr == nil {
+		correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+		response := models.NewSnapshotResponse(correlationID, "", http.StatusOK, snapshotResponse)
+		SendResponse(w, r, response, http.StatusOK)
+	} else {
+		SendEdgexError(w, r, edgexErr)
+	}
+}
+
+func DeleteSnapshotByNameHander(w http.ResponseWriter, r *http.Request) {
+	// URL parameters
+	vars := mux.Vars(r)
+	name := vars[contractsCommon.Name]
+
+	edgexErr := application.DeleteSnapshotByName(name)
+	if edgexErr == nil {
+		correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+		response := commonDTO.NewBaseResponse(correlationID, "", http.StatusOK)
+		SendResponse(w, r, response, http.StatusOK)
+	} else {
+		SendEdgexError(w, r, edgexErr)
+	}
+}
@@ -0,0 +1,34 @@
+package rest
+
+import (
+	"net/http"
+
+	contractsCommon "github.com/edgexfoundry/go-mod-core-contracts/v2/common"
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+	"github.com/gorilla/mux"
+
+	"github.com/rddigital/device-scenario/internal/application"
+	"github.com/rddigital/device-scenario/internal/models"
+)
+
+func GetAllTimerHander(w http.ResponseWriter, r *http.Request) {
+	timersResponse := application.GetAllPendingActions()
+	correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+	response := models.NewMultiPendingActionsResponse(correlationID, "", http.StatusOK, timersResponse)
+	SendResponse(w, r, response, http.StatusOK)
+}
+
+func DeleteTimerByIdHander(w http.ResponseWriter, r *http.Request) {
+	// URL parameters
+	vars := mux.Vars(r)
+	id := vars[contractsCommon.Id]
+
+	edgexErr := application.CancelPendingActionById(id)
+	if edgexErr == nil {
+		correlationID := r.Header.Get(contractsCommon.CorrelationHeader)
+		response := commonDTO.NewBaseResponse(correlationID, "", http.StatusOK)
+		SendResponse(w, r, response, http.StatusOK)
+	} else {
+		SendEdgexError(w, r, edgexErr)
+	}
+}
@@ -1,36 +1,303 @@
 package models
 
-import "strings"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	CommandActionType  = "command"
+	WebhookActionType  = "webhook"
+	PublishActionType  = "publish"
+	ScenarioActionType = "scenario"
+	SnapshotActionType = "snapshot"
+	RestoreActionType  = "restore"
+	RampActionType     = "ramp"
+	IfActionType       = "if"
+	WaitActionType     = "wait"
+)
+
+const (
+	TriggerPredicateSource  = "trigger"
+	ResourcePredicateSource = "resource"
+)
 
 type Action struct {
-	DeviceName  string `json:"deviceName" validate:"required"`
-	CommandName string `json:"commandName" validate:"required"`
-	Body        string `json:"body" validate:"required"`
+	Type string `json:"type,omitempty" validate:"omitempty,oneof='command' 'webhook' 'publish' 'scenario' 'snapshot' 'restore' 'ramp' 'if' 'wait'"`
+
+	// Command action
+	DeviceName  string     `json:"deviceName,omitempty" validate:"required_without_all=Webhook Publish Scenario Snapshot Restore Ramp If Wait Target"`
+	CommandName string     `json:"commandName,omitempty" validate:"required_without_all=Webhook Publish Scenario Snapshot Restore Ramp If Wait"`
+	Body        ActionBody `json:"body,omitempty" validate:"required_without_all=Webhook Publish Scenario Snapshot Restore Ramp If Wait"`
+	// Remote is the name of the core-command of another EdgeX instance in the configuration,
+	// the command is sent to the local core-command if it is empty
+	Remote string `json:"remote,omitempty"`
+
+	// Webhook action
+	Webhook *WebhookAction `json:"webhook,omitempty" validate:"required_if=Type webhook"`
+
+	// Publish action
+	Publish *PublishAction `json:"publish,omitempty" validate:"required_if=Type publish"`
+
+	// Scenario action
+	Scenario *ScenarioAction `json:"scenario,omitempty" validate:"required_if=Type scenario"`
+
+	// Snapshot and restore actions
+	Snapshot *SnapshotAction `json:"snapshot,omitempty" validate:"required_if=Type snapshot"`
+	Restore  *RestoreAction  `json:"restore,omitempty" validate:"required_if=Type restore"`
+
+	// Ramp action
+	Ramp *RampAction `json:"ramp,omitempty" validate:"required_if=Type ramp"`
+
+	// If action
+	If *IfAction `json:"if,omitempty" validate:"required_if=Type if"`
+
+	// Wait action
+	Wait *WaitAction `json:"wait,omitempty" validate:"required_if=Type wait"`
+
+	// Target sends the command action to all devices matching it instead of DeviceName
+	Target *DeviceTarget `json:"target,omitempty"`
+
+	// Guard skips the command action if the device is already in the target state
+	Guard *Guard `json:"guard,omitempty"`
+
+	// Verify reads back the device after the command action
+	Verify *Verification `json:"verify,omitempty"`
+
+	// Delay runs the action later, the timer restarts when the rule fires again
+	// and it is cancelled when the rule is cleared if CancelOnClear is set
+	Delay         string `json:"delay,omitempty"`
+	CancelOnClear bool   `json:"cancelOnClear,omitempty"`
+
+	// Timeout is the deadline of the action, the default deadline of the configuration
+	// is used if it is empty, except for the actions running other actions
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// ActionBody is the JSON object of parameters of the set command, the values keep their JSON type.
+// It is decoded from a JSON object or, for the older clients, from a string holding the JSON object
+type ActionBody string
+
+func (b ActionBody) MarshalJSON() ([]byte, error) {
+	trimmed := bytes.TrimSpace([]byte(b))
+	if len(trimmed) > 0 && trimmed[0] == '{' && json.Valid(trimmed) {
+		return trimmed, nil
+	}
+	return json.Marshal(string(b))
+}
+
+func (b *ActionBody) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(trimmed, []byte("null")):
+		*b = ""
+	case len(trimmed) > 0 && trimmed[0] == '"':
+		var str string
+		if err := json.Unmarshal(trimmed, &str); err != nil {
+			return err
+		}
+		*b = ActionBody(str)
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, trimmed); err != nil {
+			return err
+		}
+		*b = ActionBody(buf.String())
+	default:
+		return fmt.Errorf("body must be a JSON object")
+	}
+	return nil
+}
+
+type WebhookAction struct {
+	Method              string            `json:"method,omitempty" validate:"omitempty,oneof='GET' 'POST' 'PUT' 'PATCH' 'DELETE'"`
+	Url                 string            `json:"url" validate:"required,url"`
+	Headers             map[string]string `json:"headers,omitempty"`
+	Body                string            `json:"body,omitempty"`
+	Timeout             string            `json:"timeout,omitempty"`
+	ExpectedStatusCodes []int             `json:"expectedStatusCodes,omitempty"`
+}
+
+type PublishAction struct {
+	Topic    string `json:"topic" validate:"required"`
+	Payload  string `json:"payload,omitempty"`
+	Qos      int    `json:"qos,omitempty" validate:"min=0,max=2"`
+	Retained bool   `json:"retained,omitempty"`
+}
+
+// DeviceTarget selects the devices having all the labels, the profile and a name matching the pattern,
+// the pattern syntax is the one of path.Match, for example "light-floor2-*"
+type DeviceTarget struct {
+	Labels      []string `json:"labels,omitempty" validate:"required_without_all=ProfileName NamePattern"`
+	ProfileName string   `json:"profileName,omitempty"`
+	NamePattern string   `json:"namePattern,omitempty"`
+}
+
+// Guard issues a GET command on the resource before the command action,
+// the action is skipped if the value of the reading is already the target value.
+// The target value is the value of the resource in the body if Value is empty
+type Guard struct {
+	ResourceName string `json:"resourceName" validate:"required"`
+	Value        string `json:"value,omitempty"`
+}
+
+// Verification issues a GET command on the resource after the delay,
+// the action fails if the value of the reading is not the expected value
+type Verification struct {
+	ResourceName string `json:"resourceName" validate:"required"`
+	Expected     string `json:"expected" validate:"required"`
+	Delay        string `json:"delay,omitempty"`
+}
+
+// ScenarioAction runs the actions of a ManualScenario device or another rule
+type ScenarioAction struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// SnapshotAction reads the current values of the resources and saves them under the name
+type SnapshotAction struct {
+	Name      string             `json:"name" validate:"required"`
+	Resources []SnapshotResource `json:"resources" validate:"required,gt=0,dive"`
+}
+
+// SnapshotResource is a resource to save, it is restored by the set command with
+// the name CommandName, or ResourceName if CommandName is empty. Remote is the name
+// of the core-command of the device, see Action.Remote
+type SnapshotResource struct {
+	Remote       string `json:"remote,omitempty"`
+	DeviceName   string `json:"deviceName" validate:"required"`
+	ResourceName string `json:"resourceName" validate:"required"`
+	CommandName  string `json:"commandName,omitempty"`
+}
+
+// RestoreAction writes back the values saved under the name, the snapshot is deleted
+// after restoring if Delete is set
+type RestoreAction struct {
+	Name   string `json:"name" validate:"required"`
+	Delete bool   `json:"delete,omitempty"`
+}
+
+// RampAction writes Steps values from Start to End to the device during Duration.
+// ResourceName is the parameter in the body of the set command (default CommandName), the start value
+// is read from this resource if Start is not set. The values are rounded to Decimals decimal places.
+// Remote is the name of the core-command of the device, see Action.Remote
+type RampAction struct {
+	Remote       string   `json:"remote,omitempty"`
+	DeviceName   string   `json:"deviceName" validate:"required"`
+	CommandName  string   `json:"commandName" validate:"required"`
+	ResourceName string   `json:"resourceName,omitempty"`
+	Start        *float64 `json:"start,omitempty"`
+	End          float64  `json:"end"`
+	Duration     string   `json:"duration" validate:"required"`
+	Steps        int      `json:"steps" validate:"required,gt=0"`
+	Decimals     int      `json:"decimals,omitempty" validate:"min=0,max=6"`
+}
+
+// IfAction runs the Then actions if the predicate holds, the Else actions otherwise
+type IfAction struct {
+	Predicate Predicate `json:"predicate"`
+	Then      []Action  `json:"then,omitempty" validate:"omitempty,dive"`
+	Else      []Action  `json:"else,omitempty" validate:"omitempty,dive"`
+}
+
+// Predicate compares a value with Value. The value is the one which triggered the rule if Source is "trigger",
+// or it is read from the resource of the device with a GET command if Source is "resource", through the
+// core-command named by Remote, see Action.Remote
+type Predicate struct {
+	Source       string `json:"source" validate:"required,oneof='trigger' 'resource'"`
+	Remote       string `json:"remote,omitempty"`
+	DeviceName   string `json:"deviceName,omitempty" validate:"required_if=Source resource"`
+	ResourceName string `json:"resourceName,omitempty" validate:"required_if=Source resource"`
+	Operator     string `json:"operator" validate:"required,oneof='>' '<' '=' '!=' '>=' '<='"`
+	Value        string `json:"value"`
+}
+
+// WaitAction pauses the sequence of actions until the predicate holds, the resource is read every Interval.
+// If the predicate does not hold before Timeout, the OnTimeout actions are run and the sequence is stopped
+type WaitAction struct {
+	Predicate Predicate `json:"predicate"`
+	Interval  string    `json:"interval,omitempty"`
+	Timeout   string    `json:"timeout" validate:"required"`
+	OnTimeout []Action  `json:"onTimeout,omitempty" validate:"omitempty,dive"`
+}
+
+// ActionType returns the type of the action, the type is inferred from the content when it is empty
+func (a Action) ActionType() string {
+	switch {
+	case a.Type != "":
+		return a.Type
+	case a.Webhook != nil:
+		return WebhookActionType
+	case a.Publish != nil:
+		return PublishActionType
+	case a.Scenario != nil:
+		return ScenarioActionType
+	case a.Snapshot != nil:
+		return SnapshotActionType
+	case a.Restore != nil:
+		return RestoreActionType
+	case a.Ramp != nil:
+		return RampActionType
+	case a.If != nil:
+		return IfActionType
+	case a.Wait != nil:
+		return WaitActionType
+	default:
+		return CommandActionType
+	}
 }
 
 func ActionsToProperties(actions []Action) map[string]string {
 	properties := make(map[string]string, len(actions))
-	for _, action := range actions {
-		key := action.DeviceName + "/" + action.CommandName
-		properties[key] = action.Body
+	for index, action := range actions {
+		value, err := json.Marshal(action)
+		if err != nil {
+			continue
+		}
+		key := strconv.Itoa(index)
+		properties[key] = string(value)
 	}
 	return properties
 }
 
 func ActionsFromProperties(properties map[string]string) []Action {
-	actions := make([]Action, 0, len(properties))
+	indexes := make([]int, 0, len(properties))
+	indexActions := make(map[int]Action, len(properties))
+	legacyActions := make([]Action, 0)
 	for key, value := range properties {
-		arrStr := strings.Split(key, "/")
-		if len(arrStr) < 2 {
+		// Legacy format: key is "{deviceName}/{commandName}", value is the body
+		if arrStr := strings.Split(key, "/"); len(arrStr) >= 2 {
+			var action = Action{
+				DeviceName:  arrStr[0],
+				CommandName: arrStr[1],
+				Body:        ActionBody(value),
+			}
+			legacyActions = append(legacyActions, action)
 			continue
 		}
-		var action = Action{
-			DeviceName:  arrStr[0],
-			CommandName: arrStr[1],
-			Body:        value,
+
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		var action Action
+		if err = json.Unmarshal([]byte(value), &action); err != nil {
+			continue
 		}
-		actions = append(actions, action)
+		indexes = append(indexes, index)
+		indexActions[index] = action
+	}
+
+	sort.Ints(indexes)
+	actions := make([]Action, 0, len(properties))
+	for _, index := range indexes {
+		actions = append(actions, indexActions[index])
 	}
+	actions = append(actions, legacyActions...)
 
 	return actions
 }
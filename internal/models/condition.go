@@ -3,10 +3,18 @@ package models
 import (
 	"encoding/json"
 	"strconv"
+	"time"
 
 	"github.com/rddigital/device-scenario/internal/common"
 )
 
+// Constants related to the states of a condition, a condition is unknown when its state expired
+const (
+	ConditionTrue    = "true"
+	ConditionFalse   = "false"
+	ConditionUnknown = "unknown"
+)
+
 type Condition struct {
 	Logic string `json:"logic" validate:"required,oneof='and' 'or'"`
 	Type  string `json:"type" validate:"required"`
@@ -21,6 +29,40 @@ type Condition struct {
 	OperatorThreshold string `json:"operatorThreshold,omitempty" validate:"omitempty,oneof='>' '<' '=' '>=' '<='"`
 	ResourceThreshold string `json:"resourceThreshold,omitempty"`
 	ValueThreshold    string `json:"valueThreshold,omitempty"`
+	// MaxAge is the duration after which the state of a threshold condition expires if it is not updated,
+	// e.g. "10m". OnExpiry is the state of the expired condition, "unknown" (default) or "false"
+	MaxAge   string `json:"maxAge,omitempty"`
+	OnExpiry string `json:"onExpiry,omitempty" validate:"omitempty,oneof='unknown' 'false'"`
+}
+
+// Expired returns true if the state last updated at lastUpdated, in nanoseconds, is older than MaxAge.
+// Kuiper only calls back while the threshold is met and once when it stops being met, so a false state
+// is not refreshed by the following readings and also expires after MaxAge while the device is reporting
+func (c Condition) Expired(lastUpdated int64, now time.Time) bool {
+	if c.MaxAge == "" {
+		return false
+	}
+	maxAge, err := time.ParseDuration(c.MaxAge)
+	if err != nil {
+		return false
+	}
+	return now.Sub(time.Unix(0, lastUpdated)) > maxAge
+}
+
+// ExpiryState returns the state of the condition once it expired
+func (c Condition) ExpiryState() string {
+	if c.OnExpiry == ConditionFalse {
+		return ConditionFalse
+	}
+	return ConditionUnknown
+}
+
+// ConditionStateOf returns the state of a condition which did not expire
+func ConditionStateOf(state bool) string {
+	if state {
+		return ConditionTrue
+	}
+	return ConditionFalse
 }
 
 func ConditionsToProperties(conditions []Condition) map[string]string {
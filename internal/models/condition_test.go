@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConditionExpired(t *testing.T) {
+	now := time.Now()
+	updated := now.Add(-10 * time.Minute).UnixNano()
+
+	tests := []struct {
+		name      string
+		condition Condition
+		want      bool
+		wantState string
+	}{
+		{"no max age", Condition{}, false, ConditionUnknown},
+		{"younger than max age", Condition{MaxAge: "15m"}, false, ConditionUnknown},
+		{"older than max age", Condition{MaxAge: "5m"}, true, ConditionUnknown},
+		{"expires to false", Condition{MaxAge: "5m", OnExpiry: ConditionFalse}, true, ConditionFalse},
+		{"invalid max age", Condition{MaxAge: "5 minutes"}, false, ConditionUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.condition.Expired(updated, now); got != tt.want {
+				t.Errorf("Expired() = %v, want %v", got, tt.want)
+			}
+			if got := tt.condition.ExpiryState(); got != tt.wantState {
+				t.Errorf("ExpiryState() = %s, want %s", got, tt.wantState)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package models
+
+// Constants related to defined status of an action execution
+const (
+	ActionSucceeded = "SUCCEEDED"
+	ActionFailed    = "FAILED"
+	ActionSkipped   = "SKIPPED"
+	ActionScheduled = "SCHEDULED"
+)
+
+const (
+	ThenBranch = "then"
+	ElseBranch = "else"
+)
+
+type ActionResult struct {
+	Index  int    `json:"index"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Devices are the devices resolved from the target of the action
+	Devices []string `json:"devices,omitempty"`
+	// Branch is the branch taken by an if action, "then" or "else", and Value is the value tested by its predicate
+	Branch string `json:"branch,omitempty"`
+	Value  string `json:"value,omitempty"`
+	// Actions are the results of the actions of the branch
+	Actions []ActionResult `json:"actions,omitempty"`
+}
+
+// ExecutionResult is the result of the actions executed when a rule is triggered
+type ExecutionResult struct {
+	RuleId    string         `json:"ruleId"`
+	RuleName  string         `json:"ruleName"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	Skipped   int            `json:"skipped"`
+	Scheduled int            `json:"scheduled"`
+	Actions   []ActionResult `json:"actions,omitempty"`
+}
+
+func NewExecutionResult(rule Rule) ExecutionResult {
+	return ExecutionResult{
+		RuleId:   rule.Id,
+		RuleName: rule.Name,
+		Actions:  make([]ActionResult, 0, len(rule.Actions)),
+	}
+}
+
+// AddAction appends the result of an action and counts it by status
+func (e *ExecutionResult) AddAction(result ActionResult) {
+	switch result.Status {
+	case ActionSucceeded:
+		e.Succeeded++
+	case ActionFailed:
+		e.Failed++
+	case ActionSkipped:
+		e.Skipped++
+	case ActionScheduled:
+		e.Scheduled++
+	}
+	e.Actions = append(e.Actions, result)
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+)
+
+// Constants related to what changed the state of a condition
+const (
+	KuiperStateSource    = "kuiper"
+	SchedulerStateSource = "scheduler"
+	// ResetStateSource is the reset of a schedule condition after the rule is evaluated
+	ResetStateSource = "reset"
+)
+
+// ConditionChange tells when the state of a condition was last updated and changed, the times
+// are in nanoseconds. Source is what made the last change
+type ConditionChange struct {
+	LastUpdated int64  `json:"lastUpdated,omitempty"`
+	LastChanged int64  `json:"lastChanged,omitempty"`
+	Source      string `json:"source,omitempty"`
+}
+
+// ConditionExplanation is the live state of a condition, Backend is the status of the Kuiper rule
+// or the interval action with the name BackendName which calls back the service for the condition
+type ConditionExplanation struct {
+	Index           int       `json:"index"`
+	Condition       Condition `json:"condition"`
+	State           string    `json:"state"`
+	Expired         bool      `json:"expired,omitempty"`
+	ConditionChange `json:",inline"`
+	BackendName     string      `json:"backendName"`
+	Backend         interface{} `json:"backend,omitempty"`
+	BackendError    string      `json:"backendError,omitempty"`
+}
+
+// RuleExplanation is the live evaluation state of a rule, Result is the combination of the condition states
+// and LastFired the last time the rule fired in nanoseconds
+type RuleExplanation struct {
+	RuleId     string                 `json:"ruleId"`
+	RuleName   string                 `json:"ruleName"`
+	AdminState string                 `json:"adminState"`
+	Result     string                 `json:"result"`
+	LastFired  int64                  `json:"lastFired,omitempty"`
+	Conditions []ConditionExplanation `json:"conditions"`
+}
+
+type RuleExplanationResponse struct {
+	commonDTO.BaseResponse `json:",inline"`
+	Explanation            RuleExplanation `json:"explanation"`
+}
+
+func NewRuleExplanationResponse(requestId string, message string, statusCode int, explanation RuleExplanation) RuleExplanationResponse {
+	return RuleExplanationResponse{
+		BaseResponse: commonDTO.NewBaseResponse(requestId, message, statusCode),
+		Explanation:  explanation,
+	}
+}
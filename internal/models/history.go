@@ -0,0 +1,65 @@
+package models
+
+import (
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+)
+
+// Constants related to what fired the actions of an execution record
+const (
+	// TriggerSource is a callback of Kuiper or support-scheduler
+	TriggerSource = "trigger"
+	// TimerSource is a delayed action
+	TimerSource = "timer"
+	// ManualSource is the TriggerScenario command of a ManualScenario device or the execute endpoint of a rule
+	ManualSource = "manual"
+)
+
+// Constants related to the outcome of an execution record
+const (
+	ExecutionSucceeded = "SUCCEEDED"
+	ExecutionFailed    = "FAILED"
+)
+
+// ExecutionRecord is the history of a firing of a rule or a ManualScenario device,
+// the times are in nanoseconds
+type ExecutionRecord struct {
+	Id              string          `json:"id"`
+	RuleId          string          `json:"ruleId,omitempty"`
+	RuleName        string          `json:"ruleName"`
+	Source          string          `json:"source"`
+	TriggerIndex    int             `json:"triggerIndex"`
+	TriggerState    bool            `json:"triggerState"`
+	TriggerValue    interface{}     `json:"triggerValue,omitempty"`
+	ConditionStates []string        `json:"conditionStates,omitempty"`
+	Started         int64           `json:"started"`
+	Duration        int64           `json:"duration"`
+	Outcome         string          `json:"outcome"`
+	Result          ExecutionResult `json:"result"`
+	// NotificationStatus is empty if the notification is not enabled
+	NotificationStatus string `json:"notificationStatus,omitempty"`
+	NotificationError  string `json:"notificationError,omitempty"`
+}
+
+// ExecutionRecordFilter selects the execution records, the empty fields are ignored
+type ExecutionRecordFilter struct {
+	RuleName string
+	Start    int64
+	End      int64
+	Outcome  string
+	Offset   int
+	Limit    int
+}
+
+type MultiExecutionRecordsResponse struct {
+	commonDTO.BaseResponse `json:",inline"`
+	TotalCount             int               `json:"totalCount"`
+	Records                []ExecutionRecord `json:"records"`
+}
+
+func NewMultiExecutionRecordsResponse(requestId string, message string, statusCode int, totalCount int, records []ExecutionRecord) MultiExecutionRecordsResponse {
+	return MultiExecutionRecordsResponse{
+		BaseResponse: commonDTO.NewBaseResponse(requestId, message, statusCode),
+		TotalCount:   totalCount,
+		Records:      records,
+	}
+}
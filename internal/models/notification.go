@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/rddigital/device-scenario/internal/common"
+)
+
+// Notification is the content of the notification sent when the rule is triggered,
+// Content is a template like the webhook body
+type Notification struct {
+	Content     string   `json:"content,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Severity    string   `json:"severity,omitempty" validate:"omitempty,oneof='MINOR' 'NORMAL' 'CRITICAL'"`
+	Labels      []string `json:"labels,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+func NotificationToProperties(notification Notification) map[string]string {
+	properties := make(map[string]string)
+	properties[common.NotificationContentProperty] = notification.Content
+	properties[common.NotificationCategoryProperty] = notification.Category
+	properties[common.NotificationSeverityProperty] = notification.Severity
+	properties[common.NotificationDescriptionProperty] = notification.Description
+	// the labels are JSON encoded, a label may contain a comma
+	if len(notification.Labels) > 0 {
+		labels, _ := json.Marshal(notification.Labels)
+		properties[common.NotificationLabelsProperty] = string(labels)
+	}
+	return properties
+}
+
+func NotificationFromProperties(properties map[string]string) Notification {
+	var notification = Notification{
+		Content:     properties[common.NotificationContentProperty],
+		Category:    properties[common.NotificationCategoryProperty],
+		Severity:    properties[common.NotificationSeverityProperty],
+		Description: properties[common.NotificationDescriptionProperty],
+	}
+	labels := properties[common.NotificationLabelsProperty]
+	if err := json.Unmarshal([]byte(labels), &notification.Labels); err != nil && labels != "" {
+		// the labels saved before they were JSON encoded are separated by commas
+		notification.Labels = strings.Split(labels, ",")
+	}
+	return notification
+}
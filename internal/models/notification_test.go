@@ -0,0 +1,44 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rddigital/device-scenario/internal/common"
+)
+
+func TestNotificationProperties(t *testing.T) {
+	notification := Notification{
+		Content:  "temperature {{.Value}}",
+		Category: "hvac",
+		Severity: "CRITICAL",
+		Labels:   []string{"floor-2", "zone a,b"},
+	}
+	properties := NotificationToProperties(notification)
+	if got := properties[common.NotificationLabelsProperty]; got != `["floor-2","zone a,b"]` {
+		t.Errorf("labels property = %s, want the JSON encoded labels", got)
+	}
+	if got := NotificationFromProperties(properties); !reflect.DeepEqual(got, notification) {
+		t.Errorf("NotificationFromProperties() = %+v, want %+v", got, notification)
+	}
+}
+
+func TestNotificationFromPropertiesLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels string
+		want   []string
+	}{
+		{"no labels", "", nil},
+		{"JSON encoded", `["floor-2","zone a,b"]`, []string{"floor-2", "zone a,b"}},
+		{"comma separated", "floor-2,hvac", []string{"floor-2", "hvac"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := NotificationFromProperties(map[string]string{common.NotificationLabelsProperty: tt.labels})
+			if !reflect.DeepEqual(notification.Labels, tt.want) {
+				t.Errorf("labels = %q, want %q", notification.Labels, tt.want)
+			}
+		})
+	}
+}
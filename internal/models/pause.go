@@ -0,0 +1,37 @@
+package models
+
+import (
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+)
+
+// PauseRequest pauses the auto rules and the ManualScenario triggers, except the Exempt rules and scenarios.
+// The service resumes by itself after Duration, e.g. "2h", or at ResumeAt, in nanoseconds, if one is set
+type PauseRequest struct {
+	commonDTO.BaseRequest `json:",inline"`
+	Duration              string   `json:"duration,omitempty"`
+	ResumeAt              int64    `json:"resumeAt,omitempty"`
+	Exempt                []string `json:"exempt,omitempty"`
+	Reason                string   `json:"reason,omitempty"`
+}
+
+// PauseState is the maintenance mode of the service. Since and ResumeAt are in nanoseconds,
+// ResumeAt is 0 if the service only resumes with the resume API
+type PauseState struct {
+	Paused   bool     `json:"paused"`
+	Since    int64    `json:"since,omitempty"`
+	ResumeAt int64    `json:"resumeAt,omitempty"`
+	Exempt   []string `json:"exempt,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+}
+
+type PauseStateResponse struct {
+	commonDTO.BaseResponse `json:",inline"`
+	Pause                  PauseState `json:"pause"`
+}
+
+func NewPauseStateResponse(requestId string, message string, statusCode int, pause PauseState) PauseStateResponse {
+	return PauseStateResponse{
+		BaseResponse: commonDTO.NewBaseResponse(requestId, message, statusCode),
+		Pause:        pause,
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+)
+
+// TriggerQueueStatus is the state of the queue of the callbacks of the conditions. Depth is the number
+// of queued callbacks, Running the number of rules whose callback is running
+type TriggerQueueStatus struct {
+	Workers  int    `json:"workers"`
+	Capacity int    `json:"capacity"`
+	Overflow string `json:"overflow"`
+	Depth    int    `json:"depth"`
+	Running  int    `json:"running"`
+	Dropped  uint64 `json:"dropped"`
+	Rejected uint64 `json:"rejected"`
+}
+
+type TriggerQueueStatusResponse struct {
+	commonDTO.BaseResponse `json:",inline"`
+	Queue                  TriggerQueueStatus `json:"queue"`
+}
+
+func NewTriggerQueueStatusResponse(requestId string, message string, statusCode int, queue TriggerQueueStatus) TriggerQueueStatusResponse {
+	return TriggerQueueStatusResponse{
+		BaseResponse: commonDTO.NewBaseResponse(requestId, message, statusCode),
+		Queue:        queue,
+	}
+}
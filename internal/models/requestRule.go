@@ -8,3 +8,13 @@ type RuleRequest struct {
 	commonDTO.BaseRequest `json:",inline"`
 	Rule                  Rule `json:"rule"`
 }
+
+// ExecuteRuleRequest runs the actions of a rule immediately, whatever the states of its conditions.
+// A locked rule is only executed with IgnoreAdminState. With DryRun the effects of the actions are
+// recorded instead of sent, and the resources are read from Readings
+type ExecuteRuleRequest struct {
+	commonDTO.BaseRequest `json:",inline"`
+	IgnoreAdminState      bool               `json:"ignoreAdminState,omitempty"`
+	DryRun                bool               `json:"dryRun,omitempty"`
+	Readings              []SimulatedReading `json:"readings,omitempty" validate:"omitempty,dive"`
+}
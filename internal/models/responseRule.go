@@ -27,3 +27,39 @@ func NewMultiRulesResponse(requestId string, message string, statusCode int, rul
 		Rules:        rules,
 	}
 }
+
+// RuleExecution is the result of a manual execution of a rule, the notification status is empty
+// if the notification is not enabled and the effects are only set by a dry run
+type RuleExecution struct {
+	DryRun             bool              `json:"dryRun"`
+	Result             ExecutionResult   `json:"result"`
+	NotificationStatus string            `json:"notificationStatus,omitempty"`
+	NotificationError  string            `json:"notificationError,omitempty"`
+	Effects            []SimulatedEffect `json:"effects,omitempty"`
+}
+
+type RuleExecutionResponse struct {
+	commonDTO.BaseResponse `json:",inline"`
+	Execution              RuleExecution `json:"execution"`
+}
+
+func NewRuleExecutionResponse(requestId string, message string, statusCode int, execution RuleExecution) RuleExecutionResponse {
+	return RuleExecutionResponse{
+		BaseResponse: commonDTO.NewBaseResponse(requestId, message, statusCode),
+		Execution:    execution,
+	}
+}
+
+// RuleWarningsResponse is the response of the add and the update of a rule, Warnings are
+// the possible conflicts of its commands with the other rules
+type RuleWarningsResponse struct {
+	commonDTO.BaseResponse `json:",inline"`
+	Warnings               []string `json:"warnings,omitempty"`
+}
+
+func NewRuleWarningsResponse(requestId string, message string, statusCode int, warnings []string) RuleWarningsResponse {
+	return RuleWarningsResponse{
+		BaseResponse: commonDTO.NewBaseResponse(requestId, message, statusCode),
+		Warnings:     warnings,
+	}
+}
@@ -12,9 +12,33 @@ type Rule struct {
 	Name         string            `json:"name,omitempty"`
 	Description  string            `json:"description,omitempty"`
 	AdminState   models.AdminState `json:"adminState,omitempty" validate:"omitempty,oneof='UNLOCKED' 'LOCKED'"`
-	Actions      []Action          `json:"actions,omitempty"`
+	Actions      []Action          `json:"actions,omitempty" validate:"omitempty,dive"`
 	NotifyEnable string            `json:"notifyEnable,omitempty" validate:"omitempty,oneof='true' 'false'"`
+	Notification *Notification     `json:"notification,omitempty"`
 	Conditions   []Condition       `json:"conditions,omitempty"`
+	// Timeout is the deadline of the execution of the actions, the default deadline
+	// of the configuration is used if it is empty
+	Timeout string `json:"timeout,omitempty"`
+	// Priority arbitrates the commands of the rules, a command is not sent while an active rule
+	// with a higher priority writes another value to the same resource. The default priority is 0
+	Priority *int `json:"priority,omitempty"`
+}
+
+// PriorityValue returns the priority of the rule, 0 if it is not set
+func (r Rule) PriorityValue() int {
+	if r.Priority == nil {
+		return 0
+	}
+	return *r.Priority
+}
+
+// NotificationEnabled returns true if NotifyEnable is true,
+// or the notification is configured and NotifyEnable is not false
+func (r Rule) NotificationEnabled() bool {
+	if enable, err := strconv.ParseBool(r.NotifyEnable); err == nil {
+		return enable
+	}
+	return r.Notification != nil
 }
 
 func RuleToProperties(rule Rule) map[string]models.ProtocolProperties {
@@ -29,6 +53,21 @@ func RuleToProperties(rule Rule) map[string]models.ProtocolProperties {
 	notifyEnableProperty[common.NotifyEnableProperty] = rule.NotifyEnable
 	protocol[common.NotifyEnableProperty] = notifyEnableProperty
 
+	if rule.Notification != nil {
+		protocol[common.NotificationProperty] = NotificationToProperties(*rule.Notification)
+	}
+
+	executionProperty := make(map[string]string)
+	if rule.Timeout != "" {
+		executionProperty[common.ExecutionTimeoutProperty] = rule.Timeout
+	}
+	if rule.Priority != nil {
+		executionProperty[common.ExecutionPriorityProperty] = strconv.Itoa(*rule.Priority)
+	}
+	if len(executionProperty) > 0 {
+		protocol[common.ExecutionProperty] = executionProperty
+	}
+
 	conditionsProperty := ConditionsToProperties(rule.Conditions)
 	if len(conditionsProperty) > 0 {
 		protocol[common.ConditionsProperty] = conditionsProperty
@@ -53,11 +92,23 @@ func RuleFromDevice(d models.Device) (rule Rule, ok bool) {
 		rule.NotifyEnable = pp[common.NotifyEnableProperty]
 	}
 
+	if pp, ok := d.Protocols[common.NotificationProperty]; ok {
+		notification := NotificationFromProperties(pp)
+		rule.Notification = &notification
+	}
+
+	if pp, ok := d.Protocols[common.ExecutionProperty]; ok {
+		rule.Timeout = pp[common.ExecutionTimeoutProperty]
+		if priority, err := strconv.Atoi(pp[common.ExecutionPriorityProperty]); err == nil {
+			rule.Priority = &priority
+		}
+	}
+
 	if pp, ok := d.Protocols[common.ActionsProperty]; ok {
 		rule.Actions = ActionsFromProperties(pp)
 	} else {
-		// Require (Actions != nil) or (Actions = nil and NotifyEnable = true)
-		if enable, _ := strconv.ParseBool(rule.NotifyEnable); !enable {
+		// Require (Actions != nil) or (Actions = nil and notification enabled)
+		if !rule.NotificationEnabled() {
 			return Rule{}, false
 		}
 	}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"github.com/edgexfoundry/go-mod-core-contracts/v2/dtos"
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+)
+
+// Constants related to the effects recorded by a dry run
+const (
+	CommandEffect        = "command"
+	WebhookEffect        = "webhook"
+	PublishEffect        = "publish"
+	RampEffect           = "ramp"
+	SnapshotEffect       = "snapshot"
+	DeleteSnapshotEffect = "deleteSnapshot"
+	ScheduleEffect       = "schedule"
+	NotificationEffect   = "notification"
+)
+
+// SimulatedReading is the hypothetical value of a resource of a device during a dry run
+type SimulatedReading struct {
+	DeviceName   string `json:"deviceName" validate:"required"`
+	ResourceName string `json:"resourceName" validate:"required"`
+	Value        string `json:"value"`
+}
+
+// SimulationRequest is a dry run of a new rule or of an existing rule with the name RuleName.
+// If both are set, Rule is the new definition of the existing rule.
+// The condition states are ConditionStates if set, otherwise a threshold condition is evaluated
+// with the reading of its resource or keeps its current state, and a schedule condition is only
+// true if it is the trigger. TriggerIndex is the condition whose callback is simulated
+type SimulationRequest struct {
+	commonDTO.BaseRequest `json:",inline"`
+	Rule                  *Rule              `json:"rule,omitempty" validate:"required_without=RuleName"`
+	RuleName              string             `json:"ruleName,omitempty" validate:"required_without=Rule"`
+	ConditionStates       []bool             `json:"conditionStates,omitempty"`
+	Readings              []SimulatedReading `json:"readings,omitempty" validate:"omitempty,dive"`
+	TriggerIndex          int                `json:"triggerIndex" validate:"gte=0"`
+}
+
+// SimulatedEffect is a command, request or notification which would be sent by the actions,
+// only the fields of its type are set
+type SimulatedEffect struct {
+	Type string `json:"type"`
+	// command
+	Remote      string            `json:"remote,omitempty"`
+	DeviceName  string            `json:"deviceName,omitempty"`
+	CommandName string            `json:"commandName,omitempty"`
+	Parameters  map[string]string `json:"parameters,omitempty"`
+	// webhook
+	Method  string            `json:"method,omitempty"`
+	Url     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	// publish
+	Topic    string `json:"topic,omitempty"`
+	Payload  string `json:"payload,omitempty"`
+	Qos      int    `json:"qos,omitempty"`
+	Retained bool   `json:"retained,omitempty"`
+	// ramp
+	Ramp  *RampAction `json:"ramp,omitempty"`
+	Start *float64    `json:"start,omitempty"`
+	// snapshot, deleteSnapshot
+	Snapshot *Snapshot `json:"snapshot,omitempty"`
+	// schedule
+	ActionIndex *int    `json:"actionIndex,omitempty"`
+	Action      *Action `json:"action,omitempty"`
+	// notification
+	Notification *dtos.Notification `json:"notification,omitempty"`
+}
+
+// SimulationResult tells whether the rule would fire and what its actions would send. Result is
+// the combination of the condition states, Fired is false if the rule is locked. The actions are
+// executed if Result is true, even if the rule is locked, so a rule can be checked before it is enabled
+type SimulationResult struct {
+	RuleName          string            `json:"ruleName"`
+	AdminState        string            `json:"adminState"`
+	ConditionStates   []string          `json:"conditionStates"`
+	TriggerIndex      int               `json:"triggerIndex"`
+	Result            string            `json:"result"`
+	Fired             bool              `json:"fired"`
+	Execution         *ExecutionResult  `json:"execution,omitempty"`
+	Effects           []SimulatedEffect `json:"effects"`
+	NotificationError string            `json:"notificationError,omitempty"`
+}
+
+type SimulationResponse struct {
+	commonDTO.BaseResponse `json:",inline"`
+	Simulation             SimulationResult `json:"simulation"`
+}
+
+func NewSimulationResponse(requestId string, message string, statusCode int, simulation SimulationResult) SimulationResponse {
+	return SimulationResponse{
+		BaseResponse: commonDTO.NewBaseResponse(requestId, message, statusCode),
+		Simulation:   simulation,
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+)
+
+// Snapshot is the values of device resources saved by a snapshot action, the time is in nanoseconds
+type Snapshot struct {
+	Name     string          `json:"name"`
+	RuleName string          `json:"ruleName,omitempty"`
+	Created  int64           `json:"created"`
+	Values   []SnapshotValue `json:"values"`
+}
+
+type SnapshotValue struct {
+	SnapshotResource `json:",inline"`
+	Value            string `json:"value"`
+}
+
+type SnapshotResponse struct {
+	commonDTO.BaseResponse `json:",inline"`
+	Snapshot               Snapshot `json:"snapshot"`
+}
+
+func NewSnapshotResponse(requestId string, message string, statusCode int, snapshot Snapshot) SnapshotResponse {
+	return SnapshotResponse{
+		BaseResponse: commonDTO.NewBaseResponse(requestId, message, statusCode),
+		Snapshot:     snapshot,
+	}
+}
+
+type MultiSnapshotsResponse struct {
+	commonDTO.BaseResponse `json:",inline"`
+	Snapshots              []Snapshot `json:"snapshots"`
+}
+
+func NewMultiSnapshotsResponse(requestId string, message string, statusCode int, snapshots []Snapshot) MultiSnapshotsResponse {
+	return MultiSnapshotsResponse{
+		BaseResponse: commonDTO.NewBaseResponse(requestId, message, statusCode),
+		Snapshots:    snapshots,
+	}
+}
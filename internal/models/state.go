@@ -0,0 +1,11 @@
+package models
+
+// RuleState is the saved state of the conditions of a rule, it is only restored
+// if the conditions of the rule did not change. LastFired is in nanoseconds
+type RuleState struct {
+	RuleId     string            `json:"ruleId"`
+	Conditions []Condition       `json:"conditions"`
+	States     []bool            `json:"states"`
+	Changes    []ConditionChange `json:"changes"`
+	LastFired  int64             `json:"lastFired,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v2/dtos/common"
+)
+
+// PendingAction is a delayed action waiting for its timer, the times are in nanoseconds
+type PendingAction struct {
+	Id           string `json:"id"`
+	RuleId       string `json:"ruleId"`
+	RuleName     string `json:"ruleName"`
+	ActionIndex  int    `json:"actionIndex"`
+	Action       Action `json:"action"`
+	TriggerIndex int    `json:"triggerIndex"`
+	TriggerState bool   `json:"triggerState"`
+	// TriggerValue, DeviceName and ResourceName are only set by threshold conditions
+	TriggerValue interface{} `json:"triggerValue,omitempty"`
+	DeviceName   string      `json:"deviceName,omitempty"`
+	ResourceName string      `json:"resourceName,omitempty"`
+	Created      int64       `json:"created"`
+	FireAt       int64       `json:"fireAt"`
+}
+
+type MultiPendingActionsResponse struct {
+	commonDTO.BaseResponse `json:",inline"`
+	Timers                 []PendingAction `json:"timers"`
+}
+
+func NewMultiPendingActionsResponse(requestId string, message string, statusCode int, timers []PendingAction) MultiPendingActionsResponse {
+	return MultiPendingActionsResponse{
+		BaseResponse: commonDTO.NewBaseResponse(requestId, message, statusCode),
+		Timers:       timers,
+	}
+}
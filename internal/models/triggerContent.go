@@ -3,4 +3,6 @@ package models
 type ContentTrigger struct {
 	TriggerIndex *int  `json:"triggerIndex" validate:"required"`
 	TriggerState *bool `json:"triggerState" validate:"required"`
+	// TriggerValue is the reading value which changed the state of a threshold condition
+	TriggerValue interface{} `json:"triggerValue,omitempty"`
 }
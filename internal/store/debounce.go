@@ -0,0 +1,60 @@
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+// DebouncedSave saves a value to the local store at most once per delay, so that frequent changes
+// do not rewrite the whole file each time. The value is taken by snapshot when the save runs, the
+// callers only schedule the save and never wait for the disk
+type DebouncedSave struct {
+	name      string
+	delay     time.Duration
+	snapshot  func() interface{}
+	onError   func(err error)
+	pending   bool
+	timer     *time.Timer
+	mutex     sync.Mutex
+	saveMutex sync.Mutex // a save never overwrites a newer snapshot
+}
+
+func NewDebouncedSave(name string, delay time.Duration, snapshot func() interface{}, onError func(err error)) *DebouncedSave {
+	return &DebouncedSave{
+		name:     name,
+		delay:    delay,
+		snapshot: snapshot,
+		onError:  onError,
+	}
+}
+
+// Schedule saves the value after the delay, unless a save is already scheduled
+func (d *DebouncedSave) Schedule() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.pending {
+		return
+	}
+	d.pending = true
+	d.timer = time.AfterFunc(d.delay, d.Flush)
+}
+
+// Flush saves the value at once if a save is scheduled, e.g. when the service stops
+func (d *DebouncedSave) Flush() {
+	d.mutex.Lock()
+	if !d.pending {
+		d.mutex.Unlock()
+		return
+	}
+	d.pending = false
+	d.timer.Stop()
+	d.mutex.Unlock()
+
+	d.saveMutex.Lock()
+	defer d.saveMutex.Unlock()
+
+	if err := Local().Save(d.name, d.snapshot()); err != nil && d.onError != nil {
+		d.onError(err)
+	}
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDebouncedSave(t *testing.T) {
+	if err := InitStore(t.TempDir()); err != nil {
+		t.Fatalf("init store error: %v", err)
+	}
+
+	var snapshots int32
+	value := "first"
+	d := NewDebouncedSave("debounced", 30*time.Millisecond, func() interface{} {
+		atomic.AddInt32(&snapshots, 1)
+		return value
+	}, func(err error) {
+		t.Errorf("save error: %v", err)
+	})
+
+	// the changes during the delay are saved once, with the last value
+	d.Schedule()
+	value = "second"
+	d.Schedule()
+	var saved string
+	if ok, _ := Local().Load("debounced", &saved); ok {
+		t.Fatalf("value %s saved before the delay", saved)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	if ok, err := Local().Load("debounced", &saved); !ok || err != nil || saved != "second" {
+		t.Fatalf("saved value = %s (%v, %v), want second", saved, ok, err)
+	}
+	if n := atomic.LoadInt32(&snapshots); n != 1 {
+		t.Errorf("%d saves, want 1", n)
+	}
+
+	// nothing is saved if no save is scheduled
+	d.Flush()
+	if n := atomic.LoadInt32(&snapshots); n != 1 {
+		t.Errorf("%d saves after a flush without changes, want 1", n)
+	}
+}
+
+func TestDebouncedSaveFlush(t *testing.T) {
+	if err := InitStore(t.TempDir()); err != nil {
+		t.Fatalf("init store error: %v", err)
+	}
+
+	d := NewDebouncedSave("flushed", time.Hour, func() interface{} {
+		return []int{1, 2, 3}
+	}, nil)
+	d.Schedule()
+	d.Flush()
+
+	var saved []int
+	if ok, err := Local().Load("flushed", &saved); !ok || err != nil || len(saved) != 3 {
+		t.Fatalf("saved value = %v (%v, %v), want [1 2 3] at once", saved, ok, err)
+	}
+}
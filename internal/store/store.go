@@ -0,0 +1,101 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store keeps the local state of the service which must survive a restart
+type Store interface {
+	Save(name string, value interface{}) error
+	Load(name string, value interface{}) (bool, error)
+	Delete(name string) error
+}
+
+// FileStore saves each value as a JSON file in a directory
+type FileStore struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+var (
+	st Store
+)
+
+func Local() Store {
+	return st
+}
+
+// InitStore Init the local store in the directory
+func InitStore(dir string) error {
+	fs, err := NewFileStore(dir)
+	if err != nil {
+		return err
+	}
+	st = fs
+	return nil
+}
+
+func NewFileStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("create store directory '%s' error: %s", dir, err.Error())
+	}
+	return &FileStore{
+		dir: dir,
+	}, nil
+}
+
+func (fs *FileStore) Save(name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	// write to a temporary file first, so a crash never leaves a partial file
+	path := fs.path(name)
+	tmpPath := path + ".tmp"
+	if err = ioutil.WriteFile(tmpPath, data, 0640); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func (fs *FileStore) Load(name string, value interface{}) (bool, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	data, err := ioutil.ReadFile(fs.path(name))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err = json.Unmarshal(data, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (fs *FileStore) Delete(name string) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	err := os.Remove(fs.path(name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (fs *FileStore) path(name string) string {
+	return filepath.Join(fs.dir, name+".json")
+}